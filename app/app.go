@@ -23,9 +23,12 @@ import (
 	"os"
 	"os/signal"
 	"path"
+	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/go-spring/spring-core/bean"
 	"github.com/go-spring/spring-core/conf"
@@ -39,8 +42,11 @@ const (
 )
 
 const (
-	SpringProfile  = "spring.profile" // 运行环境
+	SpringProfile  = "spring.profile" // 运行环境，多个运行环境之间使用逗号分隔
 	SPRING_PROFILE = "SPRING_PROFILE"
+
+	// SpringProfilesGroup 运行环境分组的属性名前缀，例如 spring.profiles.group.prod=prod-db,prod-mq
+	SpringProfilesGroup = "spring.profiles.group"
 )
 
 var (
@@ -69,7 +75,19 @@ type Application struct {
 	banner              string             // Banner 的内容
 	bannerMode          BannerMode         // Banner 的显式模式
 	expectSysProperties []string           // 期望从系统环境变量中获取到的属性，支持正则表达式
+	sysEnvPrefixes      []string           // 需要转换成属性名称形式的环境变量的前缀
+	sysEnvVars          map[string]string  // 符合 expectSysProperties 的环境变量
 	listOfAfterPrepare  []AfterPrepareFunc // app.prepare() 执行完成之后的扩展点的集合
+	configWatcher       *configWatcher     // 配置文件监视器，为 nil 时不监视
+	cmdArgs             []string           // 需要解析的命令行参数，为 nil 时使用 os.Args[1:]
+	positionalArgs      []string           // 命令行参数中的位置参数
+	showHelp            bool               // 命令行参数是否请求帮助信息
+	defaults            []*defaultConfig   // 应用添加的内部默认配置
+	snapshotFile        string             // 属性值快照文件，为空时不输出
+
+	webMapping    *WebMapping                         // Web 路由映射表
+	gRpcServers   map[reflect.Value]*GRpcServer       // gRPC 服务列表
+	bindConsumers map[string]*ConditionalBindConsumer // 以 BIND 形式注册的消息消费者
 
 	Events  []ApplicationEvent  `autowire:"${application-event.collection:=[]?}"`
 	Runners []CommandLineRunner `autowire:"${command-line-runner.collection:=[]?}"`
@@ -79,13 +97,34 @@ type Application struct {
 
 // NewApplication Application 的构造函数
 func NewApplication() *Application {
-	return &Application{
+	app := &Application{
 		ApplicationContext:  core.NewApplicationContext(),
 		cfgLocation:         append([]string{}, DefaultConfigLocation),
 		bannerMode:          BannerModeConsole,
 		expectSysProperties: []string{`.*`},
+		sysEnvPrefixes:      []string{"SPRING_"},
+		webMapping:          NewWebMapping(),
+		gRpcServers:         make(map[reflect.Value]*GRpcServer),
+		bindConsumers:       make(map[string]*ConditionalBindConsumer),
 		exitChan:            make(chan struct{}),
 	}
+	app.Properties().SetEnv(app.lookupSysEnv)
+	app.Metadata().Register(conf.PropertyMeta{
+		Key:         SpringProfile,
+		Type:        "string",
+		Description: "设置运行环境",
+	})
+	app.Metadata().Register(conf.PropertyMeta{
+		Key:         SpringConfigImport,
+		Type:        "[]string",
+		Description: "导入其他配置文件或者目录",
+	})
+	app.Metadata().Register(conf.PropertyMeta{
+		Key:         SpringProfilesGroup,
+		Type:        "map[string][]string",
+		Description: "运行环境分组",
+	})
+	return app
 }
 
 // AddConfigLocation 添加配置文件
@@ -106,14 +145,33 @@ func (app *Application) ExpectSysProperties(pattern ...string) *Application {
 	return app
 }
 
+// MapSysEnv 将名称以 prefix 开头的环境变量转换成属性名称的形式，例如 SERVER_PORT
+// 对应 server.port，prefix 也可以是完整的环境变量名称，默认只转换 SPRING_ 开头的
+// 环境变量。其他环境变量仍然使用原来的名称，例如 JAVA_HOME 对应 java_home。
+// 引用和 value 标签不需要 MapSysEnv，属性不存在时会按照 conf.EnvName 查找环境变量，
+// 例如 ${server.max-conn} 默认读取 SERVER_MAXCONN；按照前缀绑定以及 GetProperty
+// 等直接读取属性的方式只能看到转换后的属性，需要使用 MapSysEnv。
+func (app *Application) MapSysEnv(prefix ...string) *Application {
+	app.sysEnvPrefixes = append(app.sysEnvPrefixes, prefix...)
+	return app
+}
+
+// WatchConfig 开启配置文件监视，每隔 interval 检查一次配置文件，发生变化时重新加载
+// 配置文件，并将变化的属性发布到属性层，标记了 refresh:"true" 的字段会重新绑定。
+// 字段在监视协程中直接赋值，其他协程不能不加同步地读取这些字段。
+func (app *Application) WatchConfig(interval time.Duration) *Application {
+	app.configWatcher = newConfigWatcher(app, interval)
+	return app
+}
+
 // AfterPrepare 注册一个 app.prepare() 执行完成之后的扩展点
 func (app *Application) AfterPrepare(fn AfterPrepareFunc) *Application {
 	app.listOfAfterPrepare = append(app.listOfAfterPrepare, fn)
 	return app
 }
 
-// Start 启动应用
-func (app *Application) start() {
+// start 启动应用，命令行参数请求帮助信息时只打印帮助信息，然后返回 false。
+func (app *Application) start() bool {
 
 	// 打印 Banner 内容
 	if app.bannerMode != BannerModeOff {
@@ -123,6 +181,17 @@ func (app *Application) start() {
 	// 准备上下文环境
 	app.prepare()
 
+	// 请求帮助信息时不再启动应用
+	if app.showHelp {
+		app.printHelp(os.Stdout)
+		return false
+	}
+
+	// 输出合并、解析后的属性值快照
+	if app.snapshotFile != "" {
+		app.writeSnapshotFile(app.snapshotFile)
+	}
+
 	// 执行所有 app.prepare() 之后执行的扩展点
 	for _, fn := range app.listOfAfterPrepare {
 		fn(app)
@@ -134,6 +203,14 @@ func (app *Application) start() {
 	// 依赖注入、属性绑定、初始化
 	app.AutoWireBeans()
 
+	// 这时已经记录了所有使用的属性，检查配置文件中的拼写错误
+	app.warnUnknownProperties()
+
+	// 开始监视配置文件
+	if app.configWatcher != nil {
+		app.SafeGoroutine(app.configWatcher.run)
+	}
+
 	// 执行命令行启动器
 	for _, r := range app.Runners {
 		r.Run(app)
@@ -145,6 +222,7 @@ func (app *Application) start() {
 	}
 
 	log.Info("application started")
+	return true
 }
 
 // printBanner 查找 Banner 文件然后将其打印到控制台
@@ -178,24 +256,6 @@ func (app *Application) printBanner() {
 	printBanner(banner)
 }
 
-// loadCmdArgs 加载命令行参数，形如 -name value 的参数才有效。
-func (_ *Application) loadCmdArgs() conf.Properties {
-	log.Debugf("load cmd args")
-	p := conf.New()
-	for i := 0; i < len(os.Args); i++ { // 以短线定义的参数才有效
-		if arg := os.Args[i]; strings.HasPrefix(arg, "-") {
-			k, v := arg[1:], ""
-			if i < len(os.Args)-1 && !strings.HasPrefix(os.Args[i+1], "-") {
-				v = os.Args[i+1]
-				i++
-			}
-			log.Tracef("%s=%v", k, v)
-			p.Set(k, v)
-		}
-	}
-	return p
-}
-
 // loadSystemEnv 加载系统环境变量，用户可以自定义有效环境变量的正则匹配
 func (app *Application) loadSystemEnv() conf.Properties {
 
@@ -210,56 +270,88 @@ func (app *Application) loadSystemEnv() conf.Properties {
 
 	log.Debugf("load system env")
 	p := conf.New()
+	vars := make(map[string]string)
 	for _, env := range os.Environ() {
 		if i := strings.Index(env, "="); i > 0 {
 			k, v := env[0:i], env[i+1:]
 			for _, r := range rex {
 				if r.MatchString(k) { // 符合匹配规则的才有效
-					log.Tracef("%s=%v", k, v)
-					p.Set(k, v)
+					vars[k] = v
+					key := app.sysEnvKey(k)
+					log.Tracef("%s=%v", k, conf.MaskValue(p, key, v))
+					p.Set(key, v)
+					p.SetOrigin(key, &conf.Origin{Layer: LayerSysEnv, EnvVar: k})
 					break
 				}
 			}
 		}
 	}
+	decryptProperties(p)
+	app.sysEnvVars = vars
 	return p
 }
 
+// lookupSysEnv 查找 loadSystemEnv 加载的环境变量，解析属性引用时只能看到符合
+// ExpectSysProperties 的环境变量，测试应用因此不会读取到测试进程的其他环境变量。
+func (app *Application) lookupSysEnv(name string) (string, bool) {
+	v, ok := app.sysEnvVars[name]
+	return v, ok
+}
+
+// sysEnvKey 返回环境变量对应的属性名，只有 MapSysEnv 指定的环境变量才会转换成
+// 属性名称的形式，避免 PATH、HOME 这样无关的环境变量和配置文件中的属性混淆。
+func (app *Application) sysEnvKey(name string) string {
+	for _, prefix := range app.sysEnvPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return conf.EnvKey(name)
+		}
+	}
+	return name
+}
+
 // loadProfileConfig 加载指定环境的配置文件
 func (app *Application) loadProfileConfig(profile string) conf.Properties {
+	layer := LayerAppConfig
+	if profile != "" {
+		layer = LayerProfileConfig
+	}
+
 	p := conf.New()
+	set := func(file string, properties map[string]interface{}) {
+		var lines fileLines
+		if _, err := os.Stat(file); err == nil {
+			lines = readFileLines(file)
+		}
+		sensitive := isSensitiveLocation(file)
+		for _, k := range sortedKeys(properties) {
+			v := properties[k]
+			p.Set(k, v)
+			p.SetOrigin(k, &conf.Origin{Layer: layer, File: file, Line: lines.lineOf(k), Sensitive: sensitive})
+			log.Tracef("%s=%v", k, conf.MaskValue(p, k, v))
+		}
+	}
+	im := &configImporter{profile: profile, set: set}
 	for _, configLocation := range app.cfgLocation {
-		var result map[string]interface{}
 		if ss := strings.SplitN(configLocation, ":", 2); len(ss) == 1 {
-			result = new(defaultPropertySource).Load(ss[0], profile)
+			new(defaultPropertySource).loadFiles(ss[0], profile, im.load)
 		} else {
 			if ps, ok := propertySources[ss[0]]; ok {
-				result = ps.Load(ss[1], profile)
+				im.load(configLocation, app.loadSource(ps, configLocation, profile))
 			} else {
 				panic(fmt.Errorf("unsupported config scheme %s", ss[0]))
 			}
 		}
-		for k, v := range result {
-			log.Tracef("%s=%v", k, v)
-			p.Set(k, v)
-		}
 	}
+	decryptProperties(p)
 	return p
 }
 
-// resolveProperty 解析属性值，查看其是否具有引用关系
-func (app *Application) resolveProperty(conf map[string]interface{}, key string, value interface{}) interface{} {
-	if s, o := value.(string); o && strings.HasPrefix(s, "${") {
-		refKey := s[2 : len(s)-1]
-		if refValue, ok := conf[refKey]; !ok {
-			panic(fmt.Errorf("property \"%s\" not config", refKey))
-		} else {
-			refValue = app.resolveProperty(conf, refKey, refValue)
-			conf[key] = refValue
-			return refValue
-		}
+// loadSource 加载属性源的配置，开启配置监视时支持变化通知的属性源由监视器加载。
+func (app *Application) loadSource(ps PropertySource, configLocation string, profile string) map[string]interface{} {
+	if wps, ok := ps.(WatchedPropertySource); ok && app.configWatcher != nil {
+		return app.configWatcher.load(wps, configLocation, profile)
 	}
-	return value
+	return ps.Load(strings.SplitN(configLocation, ":", 2)[1], profile)
 }
 
 // prepare 准备上下文环境
@@ -275,40 +367,141 @@ func (app *Application) prepare() {
 
 	// 将通过代码设置的属性值拷贝一份，第 1 层
 	apiConfig := conf.New()
-	app.Properties().Range(func(k string, v interface{}) { apiConfig.Set(k, v) })
+	apiConfig.SetEnv(app.lookupSysEnv)
+	app.Properties().Range(func(k string, v interface{}) {
+		apiConfig.Set(k, v)
+		apiConfig.SetOrigin(k, &conf.Origin{Layer: LayerAPI})
+	})
+	decryptProperties(apiConfig)
+
+	// 加载内部默认配置，第 6 层，特定环境的默认配置在确定运行环境之后加载
+	defaults := conf.New()
+	app.loadDefaults(defaults, "")
 
 	// 加载默认的应用配置文件，如 application.conf，第 5 层
 	appConfig := app.loadProfileConfig("")
-	p := conf.Priority(apiConfig, appConfig)
+	p := conf.Priority(apiConfig, conf.Priority(appConfig, defaults))
 
 	// 加载系统环境变量，第 3 层
 	sysEnv := app.loadSystemEnv()
 	p.InsertBefore(sysEnv, appConfig)
 
-	// 加载命令行参数，第 2 层
+	// 加载命令行参数，第 2 层，先收集 Bean 的属性元数据以便识别布尔类型的选项和输出帮助信息
+	app.CollectMetadata()
 	cmdArgs := app.loadCmdArgs()
 	p.InsertBefore(cmdArgs, sysEnv)
+	if app.showHelp {
+		return
+	}
 
-	// 加载特定环境的配置文件，如 application-test.conf
+	// 加载特定环境的配置文件，如 application-test.conf，第 4 层
 	profile := app.GetProfile()
 	if profile == "" {
 		keys := []string{SpringProfile, SPRING_PROFILE}
 		profile = cast.ToString(p.GetFirst(keys...))
 	}
-	if profile != "" {
-		app.Profile(profile) // 第 4 层
+	if profiles := expandProfiles(p, profile); len(profiles) > 0 {
+		app.Profile(strings.Join(profiles, ","))
+		app.loadProfileConfigs(p.InsertBefore, appConfig)
+		for _, s := range profiles {
+			app.loadDefaults(defaults, s)
+		}
+	}
+
+	// 将重组后的属性值写入 ApplicationContext 属性列表
+	properties, origins := app.mergeProperties(p)
+	for key, value := range properties {
+		app.Property(key, value)
+		app.Properties().SetOrigin(key, origins[key])
+	}
+
+	// 记录不会变化的属性层，以便配置文件变化时重新合并
+	if app.configWatcher != nil {
+		app.configWatcher.init(apiConfig, cmdArgs, sysEnv, defaults, properties)
+	}
+}
+
+// expandProfiles 展开逗号分隔的运行环境，分组的成员紧跟在分组之后，重复的运行环境只保留第一个。
+func expandProfiles(p conf.Properties, profile string) []string {
+
+	var (
+		result  []string
+		visited = make(map[string]bool)
+	)
+
+	var expand func(profiles []string)
+	expand = func(profiles []string) {
+		for _, s := range profiles {
+			if s = strings.TrimSpace(s); s == "" || visited[s] {
+				continue
+			}
+			visited[s] = true
+			result = append(result, s)
+
+			var members []string
+			switch v := p.Get(SpringProfilesGroup + "." + s).(type) {
+			case nil:
+			case string:
+				members = strings.Split(v, ",")
+			default:
+				members = cast.ToStringSlice(v)
+			}
+			expand(members)
+		}
+	}
+
+	expand(strings.Split(profile, ","))
+	return result
+}
+
+// loadProfileConfigs 按照声明顺序加载所有运行环境的配置文件，后面的运行环境优先级更高。
+func (app *Application) loadProfileConfigs(insertBefore func(curr, next conf.Properties) bool, appConfig conf.Properties) {
+	next := appConfig
+	for _, profile := range app.GetProfiles() {
 		profileConfig := app.loadProfileConfig(profile)
-		p.InsertBefore(profileConfig, appConfig)
+		insertBefore(profileConfig, next)
+		next = profileConfig
 	}
+}
 
+// mergeProperties 合并所有属性层的属性值并解析其中的引用，同时返回属性值的来源。
+// 来源标记了属性值已经解析过引用，以免读取时再次解析字面量 ${，引用了敏感信息的
+// 属性值也被标记为敏感信息。
+func (app *Application) mergeProperties(p conf.Properties) (map[string]interface{}, map[string]*conf.Origin) {
 	properties := map[string]interface{}{}
+	origins := map[string]*conf.Origin{}
 	p.Fill(properties)
-
-	// 将重组后的属性值写入 ApplicationContext 属性列表
 	for key, value := range properties {
-		value = app.resolveProperty(properties, key, value)
-		app.Property(key, value)
+		v, sensitive, err := conf.ResolvePropertySensitive(p, key, value)
+		if err != nil {
+			panic(err)
+		}
+		properties[key] = v
+		origins[key] = resolvedOrigin(p, key, sensitive)
+	}
+	return properties, origins
+}
+
+// resolvedOrigin 返回合并后的属性值的来源，并且标记属性值已经解析过引用。
+func resolvedOrigin(p conf.Properties, key string, sensitive bool) *conf.Origin {
+	var o conf.Origin
+	if origin := p.Origin(key); origin != nil {
+		o = *origin
 	}
+	o.Resolved = true
+	o.Sensitive = o.Sensitive || sensitive
+	return &o
+}
+
+// sortedKeys 返回排序后的属性名，同一属性层中多个写法对应同一个属性时按照属性名
+// 的顺序设置，结果不会因为 map 的遍历顺序而变化。
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 func (app *Application) close() {
@@ -342,11 +535,25 @@ func (app *Application) Run() {
 		app.ShutDown()
 	}()
 
-	app.start()
+	if !app.start() {
+		return
+	}
 	<-app.exitChan
 	app.close()
 }
 
+// Start 启动应用但是不阻塞当前 goroutine，需要调用 Stop 关闭应用，常用于测试。
+// 命令行参数请求帮助信息时只打印帮助信息，不会启动应用。
+func (app *Application) Start() {
+	app.start()
+}
+
+// Stop 关闭通过 Start 启动的应用
+func (app *Application) Stop() {
+	app.ShutDown()
+	app.close()
+}
+
 // ShutDown 关闭执行器
 func (app *Application) ShutDown() {
 	select {
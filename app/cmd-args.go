@@ -0,0 +1,249 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package app
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/log"
+)
+
+// cmdArgs 命令行参数的解析结果
+type cmdArgs struct {
+	properties map[string]interface{} // 属性值，重复的属性是 []string 类型
+	keys       []string               // 属性名，按照出现的顺序
+	args       []string               // 不是选项的位置参数
+	help       bool                   // 是否请求帮助信息
+}
+
+// negativeNumber 负数的语法，例如 -5、-0.5、-.5 和 -1e3
+var negativeNumber = regexp.MustCompile(`^-(\d+(\.\d*)?|\.\d+)([eE][-+]?\d+)?$`)
+
+// isOption 返回 s 是否是选项，单独的 - 以及负数不是选项。
+func isOption(s string) bool {
+	if len(s) < 2 || s[0] != '-' {
+		return false
+	}
+	return !negativeNumber.MatchString(s)
+}
+
+// optionName 返回选项的名称，去掉前面的短横线和后面的 =value。
+func optionName(s string) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(s, "-"), "-")
+	if j := strings.Index(name, "="); j >= 0 {
+		name = name[:j]
+	}
+	return name
+}
+
+// trimGoFlags 删除 flag 包定义的选项及其值，例如 go test 的 -test.v 和 -test.run，
+// 这些选项由 flag 包解析，不是应用的属性。属性元数据中声明的选项仍然保留。
+func trimGoFlags(args []string, metadata *conf.Metadata) []string {
+	var result []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "--" {
+			return append(result, args[i:]...)
+		}
+
+		if !isOption(arg) {
+			result = append(result, arg)
+			continue
+		}
+
+		name := optionName(arg)
+		f := flag.CommandLine.Lookup(name)
+		if f == nil {
+			result = append(result, arg)
+			continue
+		}
+		if _, ok := metadata.Get(name); ok {
+			result = append(result, arg)
+			continue
+		}
+
+		// 不是 --key=value 形式的非布尔选项，它的值是下一个参数
+		if !strings.Contains(arg, "=") && i+1 < len(args) {
+			if b, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok || !b.IsBoolFlag() {
+				i++
+			}
+		}
+	}
+	return result
+}
+
+// parseCmdArgs 按照 GNU 风格解析命令行参数，支持的形式如下:
+//
+//	--key=value 或者 -key=value
+//	--key value 或者 -key value，value 不能是选项，但可以是负数
+//	--flag，后面没有值或者元数据声明为 bool 类型时值为 true
+//	重复的属性会被合并成列表，-- 之后的参数都是位置参数
+//	-h 和 --help 请求帮助信息
+//
+// metadata 用于判断选项是否是布尔类型，可以为 nil。
+func parseCmdArgs(args []string, metadata *conf.Metadata) (*cmdArgs, error) {
+
+	r := &cmdArgs{properties: make(map[string]interface{})}
+
+	add := func(k, v string) {
+		switch old := r.properties[k].(type) {
+		case nil:
+			r.keys = append(r.keys, k)
+			r.properties[k] = v
+		case string:
+			r.properties[k] = []string{old, v}
+		case []string:
+			r.properties[k] = append(old, v)
+		}
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "--" {
+			r.args = append(r.args, args[i+1:]...)
+			break
+		}
+
+		if !isOption(arg) {
+			r.args = append(r.args, arg)
+			continue
+		}
+
+		name := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		if name == "h" || name == "help" {
+			r.help = true
+			continue
+		}
+
+		if j := strings.Index(name, "="); j >= 0 {
+			if j == 0 {
+				return nil, fmt.Errorf("invalid cmd arg %q", arg)
+			}
+			add(name[:j], name[j+1:])
+			continue
+		}
+
+		isBool := false
+		if metadata != nil {
+			if meta, ok := metadata.Get(name); ok && meta.Type == "bool" {
+				isBool = true
+			}
+		}
+
+		if i+1 < len(args) && !isOption(args[i+1]) && args[i+1] != "--" {
+			if next := args[i+1]; !isBool || next == "true" || next == "false" {
+				add(name, next)
+				i++
+				continue
+			}
+		}
+		add(name, "true")
+	}
+	return r, nil
+}
+
+// SetArgs 设置需要解析的命令行参数，不包含程序名称，默认使用 os.Args[1:]。
+func (app *Application) SetArgs(args ...string) *Application {
+	app.cmdArgs = append([]string{}, args...) // 空参数也不再使用 os.Args[1:]
+	return app
+}
+
+// Args 返回命令行参数中不是选项的位置参数，在应用启动之后有效。
+func (app *Application) Args() []string {
+	return app.positionalArgs
+}
+
+// loadCmdArgs 加载命令行参数，没有通过 SetArgs 设置时使用 os.Args[1:]，但是会删除
+// flag 包定义的选项。请求帮助信息时只做记录，由 start 打印帮助信息。
+func (app *Application) loadCmdArgs() conf.Properties {
+	log.Debugf("load cmd args")
+
+	args := app.cmdArgs
+	if args == nil && len(os.Args) > 0 {
+		args = trimGoFlags(os.Args[1:], app.Metadata())
+	}
+
+	r, err := parseCmdArgs(args, app.Metadata())
+	if err != nil {
+		panic(err)
+	}
+
+	app.showHelp = r.help
+
+	p := conf.New()
+	for _, k := range r.keys {
+		v := r.properties[k]
+		log.Tracef("%s=%v", k, conf.MaskValue(p, k, v))
+		p.Set(k, v)
+		p.SetOrigin(k, &conf.Origin{Layer: LayerCmdArgs})
+	}
+	app.positionalArgs = r.args
+	decryptProperties(p)
+	return p
+}
+
+// printHelp 根据注册的属性元数据输出帮助信息
+func (app *Application) printHelp(w io.Writer) {
+
+	name := "app"
+	if len(os.Args) > 0 {
+		name = filepath.Base(os.Args[0])
+	}
+
+	type option struct{ flag, desc string }
+	options := []option{{"-h, --help", "显示帮助信息"}}
+	for _, meta := range app.Metadata().All() {
+		flag := "--" + meta.Key
+		if meta.Type != "bool" {
+			t := meta.Type
+			if t == "" {
+				t = "value"
+			}
+			flag += "=<" + t + ">"
+		}
+		desc := meta.Description
+		if meta.Default != "" {
+			desc = strings.TrimSpace(desc + " (default: " + meta.Default + ")")
+		}
+		options = append(options, option{flag, desc})
+	}
+
+	width := 0
+	for _, o := range options {
+		if len(o.flag) > width {
+			width = len(o.flag)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Usage: %s [options] [--] [args...]\n\nOptions:\n", name)
+	for _, o := range options {
+		line := fmt.Sprintf("  %-*s  %s", width, o.flag, o.desc)
+		buf.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+	_, _ = w.Write(buf.Bytes())
+}
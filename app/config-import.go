@@ -0,0 +1,148 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/log"
+	"github.com/spf13/cast"
+)
+
+// SpringConfigImport 导入其他配置的属性名，值是逗号分隔的位置或者位置列表，
+// 位置可以是文件、目录或者 k8s:、file: 等形式，optional: 前缀表示位置可以不存在。
+// 相对路径相对于导入它的配置文件所在的目录，导入的属性值覆盖导入它的配置文件。
+// 导入的远程位置只在加载配置时获取，开启配置监视时也不会监视它们的变化，需要监视
+// 的远程位置应该通过 AddConfigLocation 添加。
+const SpringConfigImport = "spring.config.import"
+
+// configImporter 递归处理配置文件中的 spring.config.import 指令
+type configImporter struct {
+	profile string
+	set     func(file string, properties map[string]interface{})
+	stack   []string // 正在导入的配置文件，用于检测循环导入
+}
+
+// load 设置配置文件的属性值，然后按照声明顺序导入其他配置。
+func (im *configImporter) load(file string, properties map[string]interface{}) {
+
+	im.set(file, properties)
+
+	var imports []string
+	for k, v := range properties {
+		if conf.CanonicalKey(k) == conf.CanonicalKey(SpringConfigImport) {
+			if s, ok := v.(string); ok {
+				imports = strings.Split(s, ",")
+			} else {
+				imports = cast.ToStringSlice(v)
+			}
+		}
+	}
+	if len(imports) == 0 {
+		return
+	}
+
+	id := importId(file)
+	for i, s := range im.stack {
+		if s == id {
+			path := append(append([]string{}, im.stack[i:]...), id)
+			panic(fmt.Errorf("found circular config import: %s", strings.Join(path, " => ")))
+		}
+	}
+
+	im.stack = append(im.stack, id)
+	defer func() { im.stack = im.stack[:len(im.stack)-1] }()
+
+	for _, location := range imports {
+		if location = strings.TrimSpace(location); location != "" {
+			im.importLocation(filepath.Dir(trimScheme(file)), location)
+		}
+	}
+}
+
+// importLocation 导入一个位置的配置，baseDir 是导入它的配置文件所在的目录。
+func (im *configImporter) importLocation(baseDir string, location string) {
+
+	optional := strings.HasPrefix(location, "optional:")
+	location = strings.TrimPrefix(location, "optional:")
+
+	scheme, path := "", strings.TrimPrefix(location, "file:")
+	if ss := strings.SplitN(path, ":", 2); len(ss) == 2 && len(ss[0]) > 1 {
+		scheme, path = ss[0], ss[1] // 长度为 1 的是 Windows 盘符
+	}
+
+	// 远程属性源的位置不是本地路径
+	if ps, ok := propertySources[scheme].(WatchedPropertySource); ok && scheme != "" {
+		log.Info("import properties from ", location)
+		im.load(location, ps.Load(path, im.profile))
+		return
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		if optional && os.IsNotExist(err) {
+			log.Infof("optional config import %s not found", location)
+			return
+		}
+		panic(fmt.Errorf("config import %s: %v", location, err))
+	}
+
+	log.Info("import properties from ", location)
+
+	if scheme != "" {
+		ps, ok := propertySources[scheme]
+		if !ok {
+			panic(fmt.Errorf("unsupported config scheme %s", scheme))
+		}
+		im.load(scheme+":"+path, ps.Load(path, im.profile))
+		return
+	}
+
+	if stat.IsDir() {
+		new(defaultPropertySource).loadFiles(path, im.profile, im.load)
+		return
+	}
+
+	properties := make(map[string]interface{})
+	readConfigFile(path, properties)
+	im.load(path, properties)
+}
+
+// trimScheme 删除位置中的 scheme 前缀
+func trimScheme(location string) string {
+	if ss := strings.SplitN(location, ":", 2); len(ss) == 2 && len(ss[0]) > 1 {
+		return ss[1]
+	}
+	return location
+}
+
+// importId 返回用于检测循环导入的配置文件标识
+func importId(location string) string {
+	path := trimScheme(location)
+	if abs, err := filepath.Abs(path); err == nil {
+		return strings.TrimSuffix(location, path) + abs
+	}
+	return location
+}
@@ -17,22 +17,15 @@
 package app
 
 import (
-	"bytes"
 	"io/ioutil"
 	"os"
-	"sort"
+	"path/filepath"
+	"strings"
 
+	"github.com/go-spring/spring-core/conf"
 	"github.com/go-spring/spring-core/util"
-	"github.com/magiconair/properties"
-	"github.com/spf13/viper"
 )
 
-func init() {
-	RegisterFileConfigReader(".properties", propertiesReadBuffer)
-	RegisterFileConfigReader(".yaml", viperReadBuffer("yaml"))
-	RegisterFileConfigReader(".toml", viperReadBuffer("toml"))
-}
-
 // ConfigReader 配置读取器接口
 type ConfigReader interface {
 	FileExt() string // 文件扩展名
@@ -40,12 +33,14 @@ type ConfigReader interface {
 	ReadBuffer(buffer []byte, out map[string]interface{})
 }
 
-// configReaders 配置读取器集合
-var configReaders []ConfigReader
-
-// RegisterConfigReader 注册配置读取器
+// RegisterConfigReader 注册配置读取器，它和 conf.RegisterReader 使用同一个注册表，
+// 配置类型是不含点号的文件扩展名，同一种格式的配置在所有地方都使用相同的方式解析。
 func RegisterConfigReader(reader ConfigReader) {
-	configReaders = append(configReaders, reader)
+	conf.RegisterReader(strings.TrimPrefix(reader.FileExt(), "."), func(b []byte) (map[string]interface{}, error) {
+		out := make(map[string]interface{})
+		reader.ReadBuffer(b, out)
+		return out, nil
+	})
 }
 
 // RegisterFileConfigReader 注册基于文件的配置读取器
@@ -80,37 +75,27 @@ func (r *FileConfigReader) ReadBuffer(buffer []byte, out map[string]interface{})
 	r.fn(buffer, out)
 }
 
-// propertiesReadBuffer 使用 properties 读取配置文件
-func propertiesReadBuffer(buffer []byte, out map[string]interface{}) {
-
-	p := properties.NewProperties()
-	p.DisableExpansion = true
+// configFileExts 返回支持的配置文件扩展名，按照 conf.ReaderTypes 的顺序。
+func configFileExts() []string {
+	var exts []string
+	for _, configType := range conf.ReaderTypes() {
+		exts = append(exts, "."+configType)
+	}
+	return exts
+}
 
-	err := p.Load(buffer, properties.UTF8)
+// readConfigBuffer 按照配置类型解析配置内容，configType 为空时根据内容推测配置类型。
+func readConfigBuffer(b []byte, configType string, out map[string]interface{}) {
+	m, err := conf.ReadBytes(b, configType)
 	util.Panic(err).When(err != nil)
-
-	for _, key := range p.Keys() {
-		value, _ := p.Get(key)
-		out[key] = value
+	for key, val := range m {
+		out[key] = val
 	}
 }
 
-// viperReadBuffer 使用 viper 读取配置文件
-func viperReadBuffer(fileType string) FnReadBuffer {
-	return func(buffer []byte, out map[string]interface{}) {
-
-		v := viper.New()
-		v.SetConfigType(fileType)
-
-		err := v.ReadConfig(bytes.NewBuffer(buffer))
-		util.Panic(err).When(err != nil)
-
-		keys := v.AllKeys()
-		sort.Strings(keys)
-
-		for _, key := range keys {
-			val := v.Get(key)
-			out[key] = val
-		}
-	}
+// readConfigFile 按照扩展名读取配置文件
+func readConfigFile(filename string, out map[string]interface{}) {
+	b, err := ioutil.ReadFile(filename)
+	util.Panic(err).When(err != nil)
+	readConfigBuffer(b, filepath.Ext(filename), out)
 }
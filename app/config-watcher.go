@@ -0,0 +1,225 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package app
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/log"
+)
+
+// configWatcher 通过轮询的方式监视配置文件，文件变化时重新加载配置文件并发布变化的属性。
+type configWatcher struct {
+	app      *Application
+	interval time.Duration
+	mutex    sync.Mutex // 配置文件和属性源的变化可能同时发生
+
+	apiConfig conf.Properties // 代码设置的属性值
+	cmdArgs   conf.Properties // 命令行参数
+	sysEnv    conf.Properties // 系统环境变量
+	defaults  conf.Properties // 内部默认配置
+
+	contents   map[string][]byte                 // 配置文件的内容，文件不存在时为 nil
+	properties map[string]interface{}            // 最近一次成功发布的属性值
+	sources    map[string]map[string]interface{} // 支持变化通知的属性源最新的属性值
+}
+
+// newConfigWatcher configWatcher 的构造函数
+func newConfigWatcher(app *Application, interval time.Duration) *configWatcher {
+	return &configWatcher{
+		app:      app,
+		interval: interval,
+		sources:  make(map[string]map[string]interface{}),
+	}
+}
+
+// sourceKey 返回属性源的属性值在 sources 中的键
+func sourceKey(configLocation string, profile string) string {
+	return configLocation + "#" + profile
+}
+
+// load 加载支持变化通知的属性源，只在第一次加载时获取属性值，之后使用监视到的属性
+// 值，避免重新加载配置文件时再次获取所有的远程配置。调用者需要持有 w.mutex 或者
+// 监视尚未开始。
+func (w *configWatcher) load(ps WatchedPropertySource, configLocation string, profile string) map[string]interface{} {
+	key := sourceKey(configLocation, profile)
+	if properties, ok := w.sources[key]; ok {
+		return properties
+	}
+	ss := strings.SplitN(configLocation, ":", 2)
+	properties := ps.Load(ss[1], profile)
+	w.sources[key] = properties
+	return properties
+}
+
+// init 记录不会变化的属性层以及当前配置文件的内容
+func (w *configWatcher) init(apiConfig, cmdArgs, sysEnv, defaults conf.Properties, properties map[string]interface{}) {
+	w.apiConfig = apiConfig
+	w.cmdArgs = cmdArgs
+	w.sysEnv = sysEnv
+	w.defaults = defaults
+	w.properties = properties
+	w.contents = w.readFiles()
+}
+
+// files 返回需要监视的配置文件，只监视默认属性源的配置文件。
+func (w *configWatcher) files() []string {
+	var files []string
+	ps := new(defaultPropertySource)
+	for _, configLocation := range w.app.cfgLocation {
+		if strings.Contains(configLocation, ":") {
+			continue
+		}
+		files = append(files, ps.files(configLocation, "")...)
+		for _, profile := range w.app.GetProfiles() {
+			files = append(files, ps.files(configLocation, profile)...)
+		}
+	}
+	return files
+}
+
+// readFiles 读取所有需要监视的配置文件的内容
+func (w *configWatcher) readFiles() map[string][]byte {
+	contents := make(map[string][]byte)
+	for _, filename := range w.files() {
+		if b, err := ioutil.ReadFile(filename); err == nil {
+			contents[filename] = b
+		} else {
+			contents[filename] = nil
+		}
+	}
+	return contents
+}
+
+// run 定期检查配置文件，同时监视支持变化通知的属性源，直到应用退出。
+func (w *configWatcher) run() {
+
+	stops := w.watchSources()
+	defer func() {
+		for _, stop := range stops {
+			stop()
+		}
+	}()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.app.Context().Done():
+			return
+		case <-ticker.C:
+			w.mutex.Lock()
+			w.check()
+			w.mutex.Unlock()
+		}
+	}
+}
+
+// watchSources 监视配置位置中支持变化通知的属性源，属性源变化时使用新的属性值替换
+// 这个位置的配置，然后重新合并配置。通过 spring.config.import 导入的位置不会被监视。
+func (w *configWatcher) watchSources() []func() {
+	var stops []func()
+	for _, configLocation := range w.app.cfgLocation {
+		ss := strings.SplitN(configLocation, ":", 2)
+		if len(ss) == 1 {
+			continue
+		}
+		ps, ok := propertySources[ss[0]].(WatchedPropertySource)
+		if !ok {
+			continue
+		}
+		for _, profile := range append([]string{""}, w.app.GetProfiles()...) {
+			key := sourceKey(configLocation, profile)
+			stop, err := ps.Watch(ss[1], profile, func(properties map[string]interface{}) {
+				w.mutex.Lock()
+				defer w.mutex.Unlock()
+				w.sources[key] = properties
+				w.reload()
+			})
+			if err != nil {
+				log.Errorf("watch %s error: %v", configLocation, err)
+				continue
+			}
+			stops = append(stops, stop)
+		}
+	}
+	return stops
+}
+
+// check 检查配置文件是否发生变化，发生变化时重新加载。
+func (w *configWatcher) check() {
+
+	changed := false
+	contents := w.readFiles()
+	for filename, b := range contents {
+		if old, ok := w.contents[filename]; !ok || !bytes.Equal(old, b) || (old == nil) != (b == nil) {
+			log.Infof("config file %s changed", filename)
+			changed = true
+		}
+	}
+
+	if changed {
+		w.contents = contents
+		w.reload()
+	}
+}
+
+// reload 重新加载配置文件并发布变化的属性，支持变化通知的属性源使用 sources 中
+// 的属性值，解析失败时保留原来的属性值。
+func (w *configWatcher) reload() {
+
+	defer func() {
+		if err := recover(); err != nil {
+			log.Errorf("reload config error: %v", err)
+		}
+	}()
+
+	appConfig := w.app.loadProfileConfig("")
+	p := conf.Priority(w.apiConfig, conf.Priority(appConfig, w.defaults))
+	p.InsertBefore(w.sysEnv, appConfig)
+	p.InsertBefore(w.cmdArgs, w.sysEnv)
+
+	w.app.loadProfileConfigs(p.InsertBefore, appConfig)
+
+	properties, newOrigins := w.app.mergeProperties(p)
+	changes := conf.Diff(w.properties, properties)
+	if len(changes) == 0 {
+		return
+	}
+
+	// 提前记录来源，刷新时不会再次解析属性值，并且能够对日志进行脱敏，失败时恢复
+	origins := make(map[string]*conf.Origin)
+	for k := range changes {
+		origins[k] = w.app.Properties().Origin(k)
+		w.app.Properties().SetOrigin(k, newOrigins[k])
+	}
+
+	if err := w.app.RefreshProperties(changes); err != nil {
+		log.Errorf("refresh properties error: %v", err)
+		for k, o := range origins {
+			w.app.Properties().SetOrigin(k, o)
+		}
+		return
+	}
+
+	w.properties = properties
+}
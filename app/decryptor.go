@@ -0,0 +1,227 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package app
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/log"
+)
+
+// EncScheme ENC(...) 形式的加密属性值使用的解密器标识
+const EncScheme = "enc"
+
+// Decryptor 属性值解密器
+type Decryptor interface {
+
+	// Decrypt 解密属性值，对于 ENC(...) 是括号内的密文，对于 ${scheme:name} 是 name。
+	Decrypt(s string) (string, error)
+}
+
+// DecryptorFunc 函数形式的属性值解密器
+type DecryptorFunc func(s string) (string, error)
+
+// Decrypt 解密属性值
+func (f DecryptorFunc) Decrypt(s string) (string, error) {
+	return f(s)
+}
+
+// decryptors 解密器集合，key 是解密器的标识
+var decryptors = make(map[string]Decryptor)
+
+// RegisterDecryptor 注册属性值解密器，scheme 为 EncScheme 的解密器处理 ENC(...)
+// 形式的属性值，其他解密器处理 ${scheme:name} 形式的属性值，例如 ${secret:db-password}。
+func RegisterDecryptor(scheme string, d Decryptor) {
+	decryptors[strings.ToLower(scheme)] = d
+}
+
+// encValue ENC(...) 形式的加密属性值
+var encValue = regexp.MustCompile(`ENC\(([^)]*)\)`)
+
+// secretValue ${scheme:name} 形式的属性值，scheme 之后不能是 = 以免和默认值语法冲突。
+var secretValue = regexp.MustCompile(`\\?\$\{([a-zA-Z][a-zA-Z0-9_-]*):([^=}][^}]*)\}`)
+
+// decryptValue 解密属性值中的 ENC(...) 和 ${scheme:name}，返回值表示是否进行了解密。
+// 解密后的 ${ 会被转义，以免解密结果被当作属性引用。
+func decryptValue(s string) (string, bool, error) {
+
+	var (
+		err       error
+		decrypted bool
+	)
+
+	replace := func(scheme string, arg string) string {
+		d, ok := decryptors[scheme]
+		if !ok {
+			return ""
+		}
+		r, e := d.Decrypt(arg)
+		if e != nil {
+			err = fmt.Errorf("decrypt %s value error: %v", scheme, e)
+			return ""
+		}
+		decrypted = true
+		return strings.Replace(r, "${", "\\${", -1)
+	}
+
+	if _, ok := decryptors[EncScheme]; ok {
+		s = encValue.ReplaceAllStringFunc(s, func(m string) string {
+			return replace(EncScheme, encValue.FindStringSubmatch(m)[1])
+		})
+	}
+
+	s = secretValue.ReplaceAllStringFunc(s, func(m string) string {
+		ss := secretValue.FindStringSubmatch(m)
+		scheme := strings.ToLower(ss[1])
+		if _, ok := decryptors[scheme]; !ok || scheme == EncScheme || strings.HasPrefix(m, "\\") {
+			return m // 普通的属性引用或者被转义
+		}
+		return replace(scheme, ss[2])
+	})
+
+	return s, decrypted, err
+}
+
+// decryptProperties 解密属性层中所有的加密属性值，解密后的属性值的来源被标记为敏感信息。
+func decryptProperties(p conf.Properties) {
+	if len(decryptors) == 0 {
+		return
+	}
+	for _, key := range p.Keys() {
+		s, ok := p.Get(key).(string)
+		if !ok {
+			continue
+		}
+		v, decrypted, err := decryptValue(s)
+		if err != nil {
+			panic(fmt.Errorf("property %s: %v", key, err))
+		}
+		if !decrypted {
+			continue
+		}
+		origin := conf.Origin{}
+		if o := p.Origin(key); o != nil {
+			origin = *o
+		}
+		origin.Sensitive = true
+		p.Set(key, v)
+		p.SetOrigin(key, &origin)
+		log.Tracef("decrypt %s=%s", key, conf.MaskedValue)
+	}
+}
+
+// aesDecryptor 使用 AES-GCM 算法的解密器，密文是 base64 编码的 nonce 和加密数据。
+type aesDecryptor struct {
+	aead cipher.AEAD
+}
+
+// newAEAD 使用 AES 密钥创建 GCM 模式的加解密器，密钥长度必须是 16、24 或者 32 字节。
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// NewAESDecryptor 使用 AES 密钥创建 AES-GCM 解密器
+func NewAESDecryptor(key []byte) (Decryptor, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &aesDecryptor{aead: aead}, nil
+}
+
+// NewAESDecryptorFromFile 使用文件中 base64 编码的 AES 密钥创建 AES-GCM 解密器，
+// 这样密钥可以和配置文件分开存放，例如放在只有运维人员才能访问的目录中。
+func NewAESDecryptorFromFile(filename string) (Decryptor, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid aes key file %s: %v", filename, err)
+	}
+	return NewAESDecryptor(key)
+}
+
+// Decrypt 解密 base64 编码的密文
+func (d *aesDecryptor) Decrypt(s string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+	n := d.aead.NonceSize()
+	if len(b) < n {
+		return "", errors.New("ciphertext too short")
+	}
+	r, err := d.aead.Open(nil, b[:n], b[n:], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(r), nil
+}
+
+// AESEncrypt 使用 AES-GCM 算法加密属性值，返回 ENC(...) 形式的结果，用于生成配置文件中的密文。
+func AESEncrypt(key []byte, plaintext string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	b := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return "ENC(" + base64.StdEncoding.EncodeToString(b) + ")", nil
+}
+
+// fileSecretStore 基于本地文件的密钥存储，每个密钥是目录中的一个文件。
+type fileSecretStore struct {
+	dir string
+}
+
+// NewFileSecretStore 创建基于本地目录的密钥存储，${secret:name} 读取 dir/name
+// 文件的内容并删除结尾的换行符，例如 Docker 和 Kubernetes 挂载的 secret 目录。
+func NewFileSecretStore(dir string) Decryptor {
+	return &fileSecretStore{dir: dir}
+}
+
+// Decrypt 返回密钥文件的内容
+func (s *fileSecretStore) Decrypt(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(s.dir, name))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
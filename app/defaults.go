@@ -0,0 +1,82 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package app
+
+import (
+	"fmt"
+
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/log"
+)
+
+// defaultConfig 一组内部默认配置，优先级低于所有其他的属性层。
+type defaultConfig struct {
+	profile    string                 // 配置文件剖面，为空时总是生效
+	source     string                 // 配置的来源，例如模块的名称或者内嵌文件的名称
+	properties map[string]interface{} // 默认的属性值
+}
+
+// defaultConfigs 全局注册的内部默认配置
+var defaultConfigs []*defaultConfig
+
+// RegisterDefaults 注册内部默认配置，通常由基于 spring-core 的库在 init 函数中调用，
+// profile 为空时总是生效，否则只在对应的运行环境生效，source 用于记录属性值的来源。
+func RegisterDefaults(profile string, source string, properties map[string]interface{}) {
+	defaultConfigs = append(defaultConfigs, &defaultConfig{
+		profile:    profile,
+		source:     source,
+		properties: properties,
+	})
+}
+
+// RegisterDefaultsBytes 从内嵌的配置文件注册内部默认配置，configType 为空时根据内容
+// 推测配置类型，解析失败时 panic。
+func RegisterDefaultsBytes(profile string, source string, b []byte, configType string) {
+	properties, err := conf.ReadBytes(b, configType)
+	if err != nil {
+		panic(fmt.Errorf("read defaults %s error: %v", source, err))
+	}
+	RegisterDefaults(profile, source, properties)
+}
+
+// AddDefaults 为当前应用添加内部默认配置，优先级高于全局注册的内部默认配置。
+func (app *Application) AddDefaults(profile string, source string, properties map[string]interface{}) *Application {
+	app.defaults = append(app.defaults, &defaultConfig{
+		profile:    profile,
+		source:     source,
+		properties: properties,
+	})
+	return app
+}
+
+// loadDefaults 将 profile 对应的内部默认配置加载到 p 中，后注册的覆盖先注册的，
+// 应用添加的覆盖全局注册的。
+func (app *Application) loadDefaults(p conf.Properties, profile string) {
+	configs := append(append([]*defaultConfig{}, defaultConfigs...), app.defaults...)
+	for _, c := range configs {
+		if c.profile != profile {
+			continue
+		}
+		log.Debugf("load defaults from %s", c.source)
+		for _, k := range sortedKeys(c.properties) {
+			v := c.properties[k]
+			log.Tracef("%s=%v", k, conf.MaskValue(p, k, v))
+			p.Set(k, v)
+			p.SetOrigin(k, &conf.Origin{Layer: LayerDefaults, File: c.source})
+		}
+	}
+}
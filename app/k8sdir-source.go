@@ -0,0 +1,168 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-spring/spring-core/log"
+	"github.com/go-spring/spring-core/util"
+)
+
+func init() {
+	RegisterPropertySource(newK8sDirPropertySource(10 * time.Second))
+}
+
+// k8sSecretPrefix 位置以 secret: 开头时表示挂载的是 Secret，属性值都是敏感信息，
+// 例如 k8sdir:secret:/etc/secret。
+const k8sSecretPrefix = "secret:"
+
+// k8sDataLink k8s 更新挂载的卷时原子地替换这个符号链接
+const k8sDataLink = "..data"
+
+// SensitivePropertySource 属性值都是敏感信息的属性源
+type SensitivePropertySource interface {
+	PropertySource
+
+	// Sensitive 返回 fileLocation 的属性值是否都是敏感信息
+	Sensitive(fileLocation string) bool
+}
+
+// isSensitiveLocation 返回配置位置的属性值是否都是敏感信息
+func isSensitiveLocation(location string) bool {
+	if ss := strings.SplitN(location, ":", 2); len(ss) == 2 {
+		if ps, ok := propertySources[ss[0]].(SensitivePropertySource); ok {
+			return ps.Sensitive(ss[1])
+		}
+	}
+	return false
+}
+
+// k8sDirPropertySource 基于 k8s ConfigMap 和 Secret 挂载目录的属性源，目录中的每个
+// 文件是一个属性，文件名是属性名，文件内容是属性值，隐藏文件会被忽略。名称为
+// application[-profile] 加上配置文件扩展名的文件按照对应的格式进行解析。属性源是
+// 全局注册的，监视的状态保存在每次监视中，停止监视不会影响其他应用。
+type k8sDirPropertySource struct {
+	interval time.Duration // 检查 ..data 符号链接的间隔
+}
+
+// newK8sDirPropertySource k8sDirPropertySource 的构造函数
+func newK8sDirPropertySource(interval time.Duration) *k8sDirPropertySource {
+	return &k8sDirPropertySource{interval: interval}
+}
+
+// Scheme 返回属性源的标识
+func (p *k8sDirPropertySource) Scheme() string {
+	return "k8sdir"
+}
+
+// Sensitive 返回挂载的是否是 Secret
+func (p *k8sDirPropertySource) Sensitive(fileLocation string) bool {
+	return strings.HasPrefix(fileLocation, k8sSecretPrefix)
+}
+
+// Load 加载挂载目录中的属性，profile 配置文件剖面，fileLocation 挂载目录。每个文件
+// 对应的属性只在加载默认配置时返回，profile 不为空时只解析对应的 application 文件。
+func (p *k8sDirPropertySource) Load(fileLocation string, profile string) map[string]interface{} {
+
+	dir := strings.TrimPrefix(fileLocation, k8sSecretPrefix)
+	infos, err := ioutil.ReadDir(dir)
+	util.Panic(err).When(err != nil)
+
+	fileNamePrefix := "application"
+	if profile != "" {
+		fileNamePrefix += "-" + profile
+	}
+
+	result := make(map[string]interface{})
+	for _, info := range infos {
+		name := info.Name()
+		if strings.HasPrefix(name, ".") {
+			continue // 隐藏文件以及 ..data 等 k8s 内部使用的文件
+		}
+
+		// 挂载的文件是指向 ..data 目录的符号链接，需要使用 Stat 判断类型
+		filename := filepath.Join(dir, name)
+		stat, err := os.Stat(filename)
+		if err != nil || stat.IsDir() {
+			continue
+		}
+
+		if ext, ok := p.configFileExt(name); ok {
+			if strings.TrimSuffix(name, ext) != fileNamePrefix {
+				continue
+			}
+			log.Info("load properties from file ", filename)
+			readConfigFile(filename, result)
+			continue
+		}
+
+		if profile != "" {
+			continue
+		}
+
+		b, err := ioutil.ReadFile(filename)
+		util.Panic(err).When(err != nil)
+		result[name] = strings.TrimRight(string(b), "\r\n")
+	}
+	return result
+}
+
+// configFileExt 返回 application 文件的配置文件扩展名
+func (p *k8sDirPropertySource) configFileExt(name string) (string, bool) {
+	if !strings.HasPrefix(name, "application") {
+		return "", false
+	}
+	for _, ext := range configFileExts() {
+		if strings.HasSuffix(name, ext) {
+			return ext, true
+		}
+	}
+	return "", false
+}
+
+// version 返回挂载目录当前的版本，k8s 更新挂载的卷时会替换 ..data 符号链接。
+func (p *k8sDirPropertySource) version(dir string) string {
+	s, _ := os.Readlink(filepath.Join(dir, k8sDataLink))
+	return s
+}
+
+// Watch 定期检查 ..data 符号链接，发生变化时重新加载属性并调用 fn，应用使用 fn
+// 收到的属性值替换这个目录的配置。
+func (p *k8sDirPropertySource) Watch(fileLocation string, profile string, fn func(properties map[string]interface{})) (stop func(), err error) {
+	dir := strings.TrimPrefix(fileLocation, k8sSecretPrefix)
+	version := p.version(dir)
+	return pollWatch(p.interval, func() {
+		v := p.version(dir)
+		if v == version {
+			return
+		}
+		log.Infof("k8s volume %s changed", dir)
+		version = v
+
+		defer func() {
+			if err := recover(); err != nil {
+				log.Errorf("load %s error: %v", dir, err)
+			}
+		}()
+		fn(p.Load(fileLocation, profile))
+	}), nil
+}
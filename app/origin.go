@@ -0,0 +1,131 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package app
+
+import (
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/go-spring/spring-core/log"
+)
+
+// 属性层的名称，用于记录属性值的来源。
+const (
+	LayerAPI           = "api"            // 代码设置的属性
+	LayerCmdArgs       = "cmd-args"       // 命令行参数
+	LayerSysEnv        = "sys-env"        // 系统环境变量
+	LayerProfileConfig = "profile-config" // 特定环境的配置文件
+	LayerAppConfig     = "app-config"     // 默认的配置文件
+	LayerDefaults      = "defaults"       // 内部默认配置
+)
+
+// fileLines 配置文件的内容，用于查找属性所在的行号。
+type fileLines []string
+
+// readFileLines 读取配置文件的内容，读取失败时返回空的内容。
+func readFileLines(filename string) fileLines {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(string(b), "\n")
+}
+
+// lineOf 查找属性在配置文件中的行号，找不到时返回 0。这只是尽力而为的查找，
+// properties 文件按照完整的属性名查找，yaml 和 toml 文件按照层级依次查找。
+func (lines fileLines) lineOf(key string) int {
+
+	key = strings.ToLower(key)
+
+	for i, line := range lines {
+		s := strings.ToLower(strings.TrimSpace(line))
+		if strings.HasPrefix(s, key) {
+			if rest := strings.TrimSpace(s[len(key):]); rest != "" && (rest[0] == '=' || rest[0] == ':') {
+				return i + 1
+			}
+		}
+	}
+
+	line, start := 0, 0
+	for _, segment := range strings.Split(key, ".") {
+		found := false
+		for i := start; i < len(lines); i++ {
+			if ok, header := matchSegment(lines[i], segment); ok {
+				line, found = i+1, true
+				if header { // toml 的表头可能包含多个层级
+					start = i
+				} else {
+					start = i + 1
+				}
+				break
+			}
+		}
+		if !found {
+			return 0
+		}
+	}
+	return line
+}
+
+// matchSegment 返回该行是否定义了属性名中的一段，header 表示是否是 toml 的表头。
+func matchSegment(line string, segment string) (ok bool, header bool) {
+
+	s := strings.ToLower(strings.TrimSpace(line))
+	s = strings.TrimPrefix(s, "- ")
+
+	if strings.HasPrefix(s, "[") { // toml 表头，例如 [a.b] 或者 [[a.b]]
+		s = strings.Trim(s, "[] ")
+		for _, part := range strings.Split(s, ".") {
+			if strings.Trim(part, `"'`) == segment {
+				return true, true
+			}
+		}
+		return false, false
+	}
+
+	if i := strings.IndexAny(s, ":="); i > 0 {
+		if strings.Trim(strings.TrimSpace(s[:i]), `"'`) == segment {
+			return true, false
+		}
+	}
+	return false, false
+}
+
+// unknownProperties 返回配置文件中没有被任何 Bean 使用的属性，通常是拼写错误。
+func (app *Application) unknownProperties() []string {
+	var keys []string
+	p := app.Properties()
+	for _, key := range p.Keys() {
+		o := p.Origin(key)
+		if o == nil || (o.Layer != LayerAppConfig && o.Layer != LayerProfileConfig) {
+			continue
+		}
+		if !app.Metadata().Known(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// warnUnknownProperties 对配置文件中没有被使用的属性发出警告
+func (app *Application) warnUnknownProperties() {
+	for _, key := range app.unknownProperties() {
+		log.Warnf("unknown property %s (%s)", key, app.Properties().Origin(key))
+	}
+}
@@ -58,26 +58,44 @@ func (p *defaultPropertySource) Scheme() string {
 // Load 加载属性文件，profile 配置文件剖面，fileLocation 配置文件所在目录。
 func (p *defaultPropertySource) Load(fileLocation string, profile string) map[string]interface{} {
 
-	fileNamePrefix := "application"
-	if profile != "" {
-		fileNamePrefix += "-" + profile
-	}
-
 	result := make(map[string]interface{})
+	p.loadFiles(fileLocation, profile, func(_ string, properties map[string]interface{}) {
+		for k, v := range properties {
+			result[k] = v
+		}
+	})
+	return result
+}
 
-	// 从预定义的文件格式中加载属性值列表
-	for _, reader := range configReaders {
+// loadFiles 按照 conf.ReaderTypes 的顺序加载存在的配置文件，fn 接收文件名和文件中的属性值。
+func (p *defaultPropertySource) loadFiles(fileLocation string, profile string, fn func(filename string, properties map[string]interface{})) {
 
-		filename := filepath.Join(fileLocation, fileNamePrefix+reader.FileExt())
+	// 从预定义的文件格式中加载属性值列表
+	for _, filename := range p.files(fileLocation, profile) {
 		if _, err := os.Stat(filename); err != nil {
 			continue // 这里不需要警告
 		}
 
 		log.Info("load properties from file ", filename)
-		reader.ReadFile(filename, result)
+		properties := make(map[string]interface{})
+		readConfigFile(filename, properties)
+		fn(filename, properties)
 	}
+}
 
-	return result
+// files 返回可能存在的配置文件，和 configFileExts 一一对应。
+func (p *defaultPropertySource) files(fileLocation string, profile string) []string {
+
+	fileNamePrefix := "application"
+	if profile != "" {
+		fileNamePrefix += "-" + profile
+	}
+
+	var files []string
+	for _, ext := range configFileExts() {
+		files = append(files, filepath.Join(fileLocation, fileNamePrefix+ext))
+	}
+	return files
 }
 
 // configMapPropertySource 基于 k8s ConfigMap 的属性源
@@ -109,13 +127,22 @@ func (p *configMapPropertySource) Load(fileLocation string, profile string) map[
 
 	result := make(map[string]interface{})
 
+	// 没有扩展名的条目根据内容推测配置类型
+	if d.IsSet(profileFileName) {
+		log.Infof("load properties from config-map %s:%s", fileLocation, profileFileName)
+
+		if val := d.GetString(profileFileName); val != "" {
+			readConfigBuffer([]byte(val), "", result)
+		}
+	}
+
 	// 从预定义的文件格式中加载属性值列表
-	for _, reader := range configReaders {
-		if key := profileFileName + reader.FileExt(); d.IsSet(key) {
+	for _, ext := range configFileExts() {
+		if key := profileFileName + ext; d.IsSet(key) {
 			log.Infof("load properties from config-map %s:%s", fileLocation, key)
 
 			if val := d.GetString(key); val != "" {
-				reader.ReadBuffer([]byte(val), result)
+				readConfigBuffer([]byte(val), ext, result)
 			}
 		}
 	}
@@ -0,0 +1,291 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/log"
+)
+
+func init() {
+	RegisterPropertySource(NewRemotePropertySource("http", NewHTTPClient("http", nil)))
+	RegisterPropertySource(NewRemotePropertySource("https", NewHTTPClient("https", nil)))
+}
+
+// WatchedPropertySource 支持变化通知的属性源，开启配置监视时应用会调用 Watch，
+// 属性源发生变化时应用使用新的属性值替换这个位置的配置，应用退出时停止监视。
+type WatchedPropertySource interface {
+	PropertySource
+
+	// Watch 监视属性源的变化，变化时使用新的属性值调用 fn，不会阻塞。返回的 stop
+	// 停止这次监视并等待监视协程退出，不影响同一个属性源上的其他监视。
+	Watch(fileLocation string, profile string, fn func(properties map[string]interface{})) (stop func(), err error)
+}
+
+// pollWatch 启动协程每隔 interval 调用一次 check，返回的 stop 停止轮询并等待协程退出。
+func pollWatch(interval time.Duration, check func()) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				check()
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
+
+// RemoteClient 远程配置中心的客户端
+type RemoteClient interface {
+
+	// Fetch 获取 location 指定的配置，profile 是配置文件剖面。
+	Fetch(ctx context.Context, location string, profile string) (map[string]interface{}, error)
+}
+
+// RemotePropertySource 基于远程配置中心的属性源，获取失败时进行重试，全部失败时
+// 使用最近一次成功获取的本地缓存，通过轮询的方式监视配置的变化。属性源是全局注册
+// 的，本身不保存监视的状态，可以被多个应用同时使用。
+type RemotePropertySource struct {
+	scheme string
+	client RemoteClient
+
+	retries       int           // 失败后的重试次数
+	retryInterval time.Duration // 重试间隔
+	timeout       time.Duration // 每次获取的超时时间
+	cacheDir      string        // 本地缓存目录，为空时不缓存
+	pollInterval  time.Duration // 监视时的轮询间隔
+}
+
+// NewRemotePropertySource RemotePropertySource 的构造函数，scheme 是属性源的标识。
+func NewRemotePropertySource(scheme string, client RemoteClient) *RemotePropertySource {
+	return &RemotePropertySource{
+		scheme:        scheme,
+		client:        client,
+		retries:       3,
+		retryInterval: time.Second,
+		timeout:       10 * time.Second,
+		pollInterval:  30 * time.Second,
+	}
+}
+
+// Retry 设置失败后的重试次数和重试间隔
+func (s *RemotePropertySource) Retry(retries int, interval time.Duration) *RemotePropertySource {
+	s.retries = retries
+	s.retryInterval = interval
+	return s
+}
+
+// Timeout 设置每次获取配置的超时时间
+func (s *RemotePropertySource) Timeout(timeout time.Duration) *RemotePropertySource {
+	s.timeout = timeout
+	return s
+}
+
+// CacheDir 设置本地缓存目录，获取成功时写入缓存，全部失败时读取缓存。
+func (s *RemotePropertySource) CacheDir(dir string) *RemotePropertySource {
+	s.cacheDir = dir
+	return s
+}
+
+// PollInterval 设置监视配置变化时的轮询间隔
+func (s *RemotePropertySource) PollInterval(interval time.Duration) *RemotePropertySource {
+	s.pollInterval = interval
+	return s
+}
+
+// Scheme 返回属性源的标识
+func (s *RemotePropertySource) Scheme() string {
+	return s.scheme
+}
+
+// Load 获取远程配置，获取失败并且没有本地缓存时 panic。
+func (s *RemotePropertySource) Load(fileLocation string, profile string) map[string]interface{} {
+	properties, err := s.fetch(fileLocation, profile)
+	if err == nil {
+		s.writeCache(fileLocation, profile, properties)
+	} else {
+		var cacheErr error
+		if properties, cacheErr = s.readCache(fileLocation, profile); cacheErr != nil {
+			log.Errorf("read config cache of %s:%s error: %v", s.scheme, fileLocation, cacheErr)
+			panic(fmt.Errorf("load %s:%s error: %v", s.scheme, fileLocation, err))
+		}
+		log.Warnf("use cached config of %s:%s", s.scheme, fileLocation)
+	}
+	return properties
+}
+
+// fetch 获取远程配置，失败时按照设置进行重试。
+func (s *RemotePropertySource) fetch(fileLocation string, profile string) (map[string]interface{}, error) {
+	var err error
+	for i := 0; i <= s.retries; i++ {
+		if i > 0 {
+			time.Sleep(s.retryInterval)
+		}
+		var properties map[string]interface{}
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		properties, err = s.client.Fetch(ctx, fileLocation, profile)
+		cancel()
+		if err == nil {
+			return properties, nil
+		}
+		log.Warnf("fetch %s:%s error: %v (attempt %d)", s.scheme, fileLocation, err, i+1)
+	}
+	return nil, err
+}
+
+// cacheFileName 缓存文件名中不能出现的字符
+var cacheFileName = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// cacheFile 返回缓存文件的路径
+func (s *RemotePropertySource) cacheFile(fileLocation string, profile string) string {
+	name := cacheFileName.ReplaceAllString(s.scheme+"_"+fileLocation+"_"+profile, "_")
+	return filepath.Join(s.cacheDir, name+".json")
+}
+
+// writeCache 写入本地缓存，失败时只记录日志。
+func (s *RemotePropertySource) writeCache(fileLocation string, profile string, properties map[string]interface{}) {
+	if s.cacheDir == "" {
+		return
+	}
+	b, err := json.Marshal(properties)
+	if err == nil {
+		if err = os.MkdirAll(s.cacheDir, 0700); err == nil {
+			err = ioutil.WriteFile(s.cacheFile(fileLocation, profile), b, 0600)
+		}
+	}
+	if err != nil {
+		log.Warnf("write config cache error: %v", err)
+	}
+}
+
+// readCache 读取本地缓存
+func (s *RemotePropertySource) readCache(fileLocation string, profile string) (map[string]interface{}, error) {
+	if s.cacheDir == "" {
+		return nil, fmt.Errorf("no local cache")
+	}
+	b, err := ioutil.ReadFile(s.cacheFile(fileLocation, profile))
+	if err != nil {
+		return nil, err
+	}
+	var properties map[string]interface{}
+	if err = json.Unmarshal(b, &properties); err != nil {
+		return nil, err
+	}
+	return properties, nil
+}
+
+// Watch 定期获取远程配置，和这次监视上一次获取到的配置不同时调用 fn，因此第一次
+// 获取成功时总是调用 fn，调用者需要自行忽略没有变化的属性值。
+func (s *RemotePropertySource) Watch(fileLocation string, profile string, fn func(properties map[string]interface{})) (stop func(), err error) {
+	var last map[string]interface{}
+	return pollWatch(s.pollInterval, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		properties, err := s.client.Fetch(ctx, fileLocation, profile)
+		cancel()
+		if err != nil {
+			log.Warnf("watch %s:%s error: %v", s.scheme, fileLocation, err)
+			return
+		}
+		if last != nil && reflect.DeepEqual(last, properties) {
+			return
+		}
+		last = properties
+		s.writeCache(fileLocation, profile, properties)
+		fn(properties)
+	}), nil
+}
+
+// httpClient 基于 HTTP 的配置中心客户端
+type httpClient struct {
+	scheme string
+	client *http.Client
+}
+
+// NewHTTPClient 创建基于 HTTP 的配置中心客户端，scheme 是 http 或者 https，client
+// 为 nil 时使用默认的客户端。
+// 客户端使用 GET 请求获取配置，profile 作为查询参数，响应是 JSON 对象，嵌套的对象
+// 会被展开成多级属性名，例如 http://config-server/app?profile=prod。
+func NewHTTPClient(scheme string, client *http.Client) RemoteClient {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpClient{scheme: scheme, client: client}
+}
+
+// Fetch 获取配置，location 是去掉 scheme 的 URL，例如 //config-server/app
+func (c *httpClient) Fetch(ctx context.Context, location string, profile string) (map[string]interface{}, error) {
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" {
+		u.Scheme = c.scheme
+	}
+	if profile != "" {
+		q := u.Query()
+		q.Set("profile", profile)
+		u.RawQuery = q.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http status %d: %s", resp.StatusCode, b)
+	}
+	return conf.ReadBytes(b, "json")
+}
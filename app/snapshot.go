@@ -0,0 +1,54 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package app
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/log"
+)
+
+// SnapshotFile 设置快照文件，准备上下文环境之后将合并、解析后的属性值写入该文件，
+// 按照扩展名选择配置格式，敏感信息会被脱敏。可以使用 conf.DiffSnapshot 比较不同
+// 环境的快照文件。
+func (app *Application) SnapshotFile(filename string) *Application {
+	app.snapshotFile = filename
+	return app
+}
+
+// WriteSnapshot 按照 configType 输出所有属性值的快照，敏感信息会被脱敏。
+func (app *Application) WriteSnapshot(w io.Writer, configType string) error {
+	return conf.WriteProperties(w, conf.Snapshot(app.Properties()), configType)
+}
+
+// writeSnapshotFile 将属性值的快照写入文件，失败时只记录日志。
+func (app *Application) writeSnapshotFile(filename string) {
+	var buf bytes.Buffer
+	err := app.WriteSnapshot(&buf, filepath.Ext(filename))
+	if err == nil {
+		err = ioutil.WriteFile(filename, buf.Bytes(), 0600)
+	}
+	if err != nil {
+		log.Errorf("write config snapshot %s error: %v", filename, err)
+		return
+	}
+	log.Info("write config snapshot to ", filename)
+}
@@ -445,6 +445,9 @@ type BeanDefinition struct {
 	destroy *Runnable // 销毁函数
 
 	Exports map[reflect.Type]struct{} // 严格导出的接口类型
+
+	configPrefix string // 绑定属性值的前缀，为空时不绑定
+	refreshable  bool   // 属性值变化时是否按照前缀重新绑定
 }
 
 // newBeanDefinition BeanDefinition 的构造函数
@@ -562,6 +565,16 @@ func (d *BeanDefinition) GetDestroy() *Runnable {
 	return d.destroy
 }
 
+// GetConfigurationPrefix 返回 Bean 绑定属性值的前缀
+func (d *BeanDefinition) GetConfigurationPrefix() string {
+	return d.configPrefix
+}
+
+// IsRefreshable 返回属性值变化时是否按照前缀重新绑定 Bean
+func (d *BeanDefinition) IsRefreshable() bool {
+	return d.refreshable
+}
+
 // getFile 返回 Bean 注册点所在文件的名称
 func (d *BeanDefinition) GetFile() string {
 	return d.File
@@ -631,6 +644,24 @@ func (d *BeanDefinition) SetPrimary(Primary bool) *BeanDefinition {
 	return d
 }
 
+// ConfigurationProperties 设置 Bean 绑定属性值的前缀，Bean 创建后按照宽松的名称
+// 将前缀下的属性绑定到结构体的导出字段上，例如 MaxIdleConns 对应 prefix.max-idle-conns。
+// Bean 必须是结构体指针。
+func (d *BeanDefinition) ConfigurationProperties(prefix string) *BeanDefinition {
+	if t := d.Type(); t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		panic(errors.New("configuration properties bean should be *struct"))
+	}
+	d.configPrefix = prefix
+	return d
+}
+
+// Refreshable 属性值变化时按照 ConfigurationProperties 设置的前缀重新绑定 Bean，
+// 重新绑定只写入配置了的导出字段，私有字段保持不变，Bean 需要自行保证并发访问的安全。
+func (d *BeanDefinition) Refreshable() *BeanDefinition {
+	d.refreshable = true
+	return d
+}
+
 // validLifeCycleFunc 判断是否是合法的用于 Bean 生命周期控制的函数，生命周期函数的要求：
 // 至少一个参数，且第一个参数的类型必须是 Bean 的类型，没有返回值或者只能返回 error 类型值。
 func validLifeCycleFunc(fn interface{}, beanType reflect.Type) (reflect.Type, bool) {
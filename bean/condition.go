@@ -22,12 +22,18 @@ import (
 
 type ConditionContext interface {
 
-	// GetProfile 返回运行环境
+	// GetProfile 返回运行环境，多个运行环境之间使用逗号分隔
 	GetProfile() string
 
+	// GetProfiles 返回按照声明顺序排列的所有运行环境
+	GetProfiles() []string
+
 	//Properties
 	Properties() conf.Properties
 
+	// Metadata 返回属性的元数据，Condition 可以记录它使用的属性
+	Metadata() *conf.Metadata
+
 	// FindBean 查询单例 Bean，若多于 1 个则 panic；找到返回 true 否则返回 false。
 	// 它和 GetBean 的区别是它在调用后不能保证返回的 Bean 已经完成了注入和绑定过程。
 	FindBean(selector BeanSelector) (*BeanDefinition, bool)
@@ -0,0 +1,153 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bean
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Invocation 一次被拦截的方法调用
+type Invocation struct {
+	Target reflect.Value   // 被代理的 Bean
+	Method string          // 方法名称
+	Args   []reflect.Value // 方法参数，可变参数已经展开
+
+	fn    reflect.Value // 目标方法
+	chain []Interceptor // 拦截器链
+	index int           // 下一个拦截器的位置
+}
+
+// Proceed 执行拦截器链中的下一个拦截器，全部执行完后调用目标方法。
+func (inv *Invocation) Proceed() []reflect.Value {
+	if inv.index < len(inv.chain) {
+		i := inv.chain[inv.index]
+		inv.index++
+		return i.Invoke(inv)
+	}
+	return inv.fn.Call(inv.Args)
+}
+
+// Interceptor 方法拦截器，实现者可以在 Proceed 前后添加额外的逻辑，也可以不调用 Proceed。
+type Interceptor interface {
+	Invoke(inv *Invocation) []reflect.Value
+}
+
+// InterceptorFunc 函数形式的方法拦截器
+type InterceptorFunc func(inv *Invocation) []reflect.Value
+
+// Invoke 执行拦截逻辑
+func (f InterceptorFunc) Invoke(inv *Invocation) []reflect.Value {
+	return f(inv)
+}
+
+// InvocationHandler 代理对象将方法调用转发给它，返回值的个数和方法的返回值个数相同，
+// 注意返回值为 nil 的接口类型需要使用 v, _ := out[i].(T) 的形式进行类型转换。
+type InvocationHandler interface {
+	Invoke(method string, args ...interface{}) []interface{}
+}
+
+// invocationHandler InvocationHandler 的默认实现
+type invocationHandler struct {
+	target reflect.Value
+	chain  []Interceptor
+}
+
+// NewInvocationHandler invocationHandler 的构造函数，chain 按照执行顺序排列。
+func NewInvocationHandler(target reflect.Value, chain []Interceptor) InvocationHandler {
+	return &invocationHandler{target: target, chain: chain}
+}
+
+// Invoke 依次执行拦截器链然后调用目标方法
+func (h *invocationHandler) Invoke(method string, args ...interface{}) []interface{} {
+
+	fn := h.target.MethodByName(method)
+	if !fn.IsValid() {
+		panic(fmt.Errorf("can't find method:%s on type:%s", method, h.target.Type()))
+	}
+
+	fnType := fn.Type()
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		var it reflect.Type
+		if fnType.IsVariadic() && i >= fnType.NumIn()-1 {
+			it = fnType.In(fnType.NumIn() - 1).Elem()
+		} else {
+			it = fnType.In(i)
+		}
+		if arg == nil { // nil 需要转换成对应类型的零值
+			in[i] = reflect.Zero(it)
+		} else {
+			in[i] = reflect.ValueOf(arg)
+		}
+	}
+
+	inv := &Invocation{
+		Target: h.target,
+		Method: method,
+		Args:   in,
+		fn:     fn,
+		chain:  h.chain,
+	}
+
+	out := inv.Proceed()
+	result := make([]interface{}, len(out))
+	for i, o := range out {
+		result[i] = o.Interface()
+	}
+	return result
+}
+
+// proxyFactories 代理工厂集合，key 是接口类型
+var (
+	proxyMutex     sync.RWMutex
+	proxyFactories = make(map[reflect.Type]reflect.Value)
+)
+
+// RegisterProxyFactory 注册接口的代理工厂，fn 的原型为 func(InvocationHandler) I，
+// I 是接口类型。代理工厂生成的代理对象需要把 I 的每个方法都转发给 InvocationHandler，
+// 它可以手写也可以通过代码生成工具生成。只有注册了代理工厂的接口才能被拦截。
+func RegisterProxyFactory(fn interface{}) {
+
+	fnType := reflect.TypeOf(fn)
+	if fnType.Kind() != reflect.Func || fnType.NumIn() != 1 || fnType.NumOut() != 1 ||
+		fnType.In(0) != invocationHandlerType || fnType.Out(0).Kind() != reflect.Interface {
+		panic(errors.New("proxy factory must be func(bean.InvocationHandler) interface"))
+	}
+
+	proxyMutex.Lock()
+	defer proxyMutex.Unlock()
+	proxyFactories[fnType.Out(0)] = reflect.ValueOf(fn)
+}
+
+// invocationHandlerType InvocationHandler 的反射类型
+var invocationHandlerType = reflect.TypeOf((*InvocationHandler)(nil)).Elem()
+
+// NewProxy 使用注册的代理工厂为 target 创建 t 接口类型的代理对象，没有注册代理工厂时返回 false。
+func NewProxy(t reflect.Type, target reflect.Value, chain []Interceptor) (reflect.Value, bool) {
+	proxyMutex.RLock()
+	fn, ok := proxyFactories[t]
+	proxyMutex.RUnlock()
+	if !ok {
+		return reflect.Value{}, false
+	}
+	h := NewInvocationHandler(target, chain)
+	out := fn.Call([]reflect.Value{reflect.ValueOf(h)})
+	return out[0], true
+}
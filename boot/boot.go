@@ -18,16 +18,21 @@ package boot
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-spring/spring-core/app"
 	"github.com/go-spring/spring-core/bean"
 	"github.com/go-spring/spring-core/conf"
 	"github.com/go-spring/spring-core/core"
-	"github.com/go-spring/spring-core/log"
 )
 
 var gApp = app.NewApplication()
 
+// Default 返回 boot 包使用的默认应用，需要多个相互隔离的应用时请使用 app.NewApplication 创建。
+func Default() *app.Application {
+	return gApp
+}
+
 // SetBannerMode 设置 Banner 的显式模式
 func SetBannerMode(mode app.BannerMode) {
 	gApp.SetBannerMode(mode)
@@ -38,16 +43,43 @@ func ExpectSysProperties(pattern ...string) {
 	gApp.ExpectSysProperties(pattern...)
 }
 
+// MapSysEnv 将名称以 prefix 开头的环境变量转换成属性名称的形式，例如 SERVER_PORT 对应 server.port
+func MapSysEnv(prefix ...string) {
+	gApp.MapSysEnv(prefix...)
+}
+
+// WatchConfig 开启配置文件监视，每隔 interval 检查一次配置文件
+func WatchConfig(interval time.Duration) {
+	gApp.WatchConfig(interval)
+}
+
+// SetArgs 设置需要解析的命令行参数，不包含程序名称，默认使用 os.Args[1:]
+func SetArgs(args ...string) {
+	gApp.SetArgs(args...)
+}
+
+// Args 返回命令行参数中不是选项的位置参数
+func Args() []string {
+	return gApp.Args()
+}
+
+// AddDefaults 为应用添加内部默认配置，优先级低于所有其他的属性层。
+func AddDefaults(profile string, source string, properties map[string]interface{}) {
+	gApp.AddDefaults(profile, source, properties)
+}
+
+// SnapshotFile 设置属性值快照文件，准备上下文环境之后写入合并、解析后的属性值。
+func SnapshotFile(filename string) {
+	gApp.SnapshotFile(filename)
+}
+
 // AfterPrepare 注册一个 gApp.prepare() 执行完成之后的扩展点
 func AfterPrepare(fn app.AfterPrepareFunc) {
 	gApp.AfterPrepare(fn)
 }
 
-var running = false
-
 // RunApplication 快速启动 boot 应用
 func Run(cfgLocation ...string) {
-	running = true
 	gApp.AddConfigLocation(cfgLocation...)
 	gApp.Run()
 }
@@ -55,18 +87,10 @@ func Run(cfgLocation ...string) {
 // Exit 退出 boot 应用
 func Exit() {
 	gApp.ShutDown()
-	running = false
 }
 
 //////////////// SpringContext ////////////////////////
 
-func checkRunning() {
-	if running {
-		// 这条限制的原因是为了让代码更好看，例如在 AfterPrepare 中注册 Bean
-		log.Warn("use SpringContext when you can capture it")
-	}
-}
-
 // GetProfile 返回运行环境
 func GetProfile() string {
 	return gApp.GetProfile()
@@ -79,19 +103,16 @@ func SetProfile(profile string) {
 
 // Bean 注册 BeanDefinition 对象。
 func Bean(bd *bean.BeanDefinition) *bean.BeanDefinition {
-	checkRunning()
 	return gApp.RegisterBean(bd)
 }
 
 // Ref 注册单例 Bean，不指定名称，重复注册会 panic。
 func Ref(i interface{}) *bean.BeanDefinition {
-	checkRunning()
 	return gApp.RegisterBean(bean.Ref(i))
 }
 
 // Make 注册单例构造函数 Bean，不指定名称，重复注册会 panic。
 func Make(fn interface{}, tags ...string) *bean.BeanDefinition {
-	checkRunning()
 	return gApp.RegisterBean(bean.Make(fn, tags...))
 }
 
@@ -99,17 +120,25 @@ func Make(fn interface{}, tags ...string) *bean.BeanDefinition {
 // 必须给定方法名而不能通过遍历方法列表比较方法类型的方式获得函数名，因为不同方法的类型可能相同。
 // 而且 interface 的方法类型不带 receiver 而成员方法的类型带有 receiver，两者类型也不好匹配。
 func Child(selector bean.BeanSelector, method string, tags ...string) *bean.BeanDefinition {
-	checkRunning()
 	return gApp.RegisterBean(bean.Child(selector, method, tags...))
 }
 
 // MethodFunc 注册成员方法单例 Bean，不指定名称，重复注册会 panic。
 // method 形如 ServerInterface.Consumer (接口) 或 (*Server).Consumer (类型)。
 func MethodFunc(method interface{}, tags ...string) *bean.BeanDefinition {
-	checkRunning()
 	return gApp.RegisterBean(bean.MethodFunc(method, tags...))
 }
 
+// BeanFactory 注册一个根据属性配置批量生成 Bean 定义的工厂函数。
+func BeanFactory(fn interface{}, tag ...string) {
+	gApp.RegisterBeanFactory(fn, tag...)
+}
+
+// Intercept 注册一个方法拦截器，只作用于按照接口类型注入的 Bean。
+func Intercept(interceptor bean.Interceptor) *core.Advisor {
+	return gApp.Intercept(interceptor)
+}
+
 // WireBean 对外部的 Bean 进行依赖注入和属性绑定
 func WireBean(bean interface{}) {
 	gApp.WireBean(bean)
@@ -165,7 +194,6 @@ func GetDefaultProperty(key string, def interface{}) interface{} {
 
 // SetProperty 设置属性值，属性名称统一转成小写。
 func SetProperty(key string, value interface{}) {
-	checkRunning()
 	gApp.Property(key, value)
 }
 
@@ -174,6 +202,11 @@ func Properties() conf.Properties {
 	return gApp.Properties()
 }
 
+// Metadata 返回属性的元数据
+func Metadata() *conf.Metadata {
+	return gApp.Metadata()
+}
+
 // Invoke 立即执行一个一次性的任务
 func Invoke(fn interface{}, tags ...string) error {
 	return gApp.Invoke(fn, tags...)
@@ -17,65 +17,31 @@
 package boot
 
 import (
-	"fmt"
 	"reflect"
 
+	"github.com/go-spring/spring-core/app"
 	"github.com/go-spring/spring-core/bean"
-	"github.com/go-spring/spring-core/core"
-	"github.com/go-spring/spring-core/util"
 )
 
 ///////////////////// gRPC Server //////////////////////
 
-// GRpcServerMap gRPC 服务列表
-var GRpcServerMap = make(map[reflect.Value]*GRpcServer)
+// GRpcServer gRPC 服务提供者
+type GRpcServer = app.GRpcServer
 
-// RegisterGRpcServer 注册 gRPC 服务提供者，fn 是 gRPC 自动生成的服务注册函数，serviceName 是服务名称，
-// 必须对应 *_grpc.pg.go 文件里面 grpc.ServiceDesc 的 ServiceName 字段，server 是服务具体提供者对象。
-func RegisterGRpcServer(fn interface{}, serviceName string, server interface{}) *GRpcServer {
-	v := reflect.ValueOf(fn)
-	if _, ok := GRpcServerMap[v]; ok {
-		_, _, fnName := util.FileLine(fn)
-		panic(fmt.Errorf("duplicate registration, gRpcServer: %s", fnName))
-	}
-	s := newGRpcServer(serviceName, server)
-	GRpcServerMap[v] = s
-	return s
-}
-
-type GRpcServer struct {
-	server      interface{}    // 服务对象
-	serviceName string         // 服务名称
-	cond        bean.Condition // 判断条件
-}
-
-// newGRpcServer GRpcServer 的构造函数
-func newGRpcServer(serviceName string, server interface{}) *GRpcServer {
-	return &GRpcServer{server: server, serviceName: serviceName}
-}
+// GRpcServerMap 默认应用的 gRPC 服务列表
+//
+// Deprecated: 请使用 Default().GRpcServers()
+var GRpcServerMap = gApp.GRpcServers()
 
-// ServiceName 返回服务名称
-func (s *GRpcServer) ServiceName() string {
-	return s.serviceName
+// GRpcServers 返回默认应用的 gRPC 服务列表
+func GRpcServers() map[reflect.Value]*GRpcServer {
+	return gApp.GRpcServers()
 }
 
-// Server 返回服务对象
-func (s *GRpcServer) Server() interface{} {
-	return s.server
-}
-
-// WithCondition 设置一个 Condition
-func (s *GRpcServer) WithCondition(cond bean.Condition) *GRpcServer {
-	s.cond = cond
-	return s
-}
-
-// CheckCondition 成功返回 true，失败返回 false
-func (s *GRpcServer) CheckCondition(ctx core.ApplicationContext) bool {
-	if s.cond == nil {
-		return true
-	}
-	return s.cond.Matches(ctx)
+// RegisterGRpcServer 注册 gRPC 服务提供者，fn 是 gRPC 自动生成的服务注册函数，serviceName 是服务名称，
+// 必须对应 *_grpc.pg.go 文件里面 grpc.ServiceDesc 的 ServiceName 字段，server 是服务具体提供者对象。
+func RegisterGRpcServer(fn interface{}, serviceName string, server interface{}) *GRpcServer {
+	return gApp.RegisterGRpcServer(fn, serviceName, server)
 }
 
 ///////////////////// gRPC Client //////////////////////
@@ -36,6 +36,7 @@ type JUnitRunner struct {
 	Suites  []JUnitSuite `autowire:"[]?"`
 	t       *testing.T
 	waiting time.Duration
+	exit    func() // 测试结束后退出应用
 }
 
 func (r *JUnitRunner) Run(ctx core.ApplicationContext) {
@@ -44,12 +45,12 @@ func (r *JUnitRunner) Run(ctx core.ApplicationContext) {
 		for _, suite := range r.Suites {
 			suite.Test(r.t)
 		}
-		Exit()
+		r.exit()
 	})
 }
 
 // RunTestApplication 启动测试程序，waiting 是测试用例开始前的等待时间，因为不知道程序启动器何时完成
 func RunTestApplication(t *testing.T, waiting time.Duration, configLocation ...string) {
-	Ref(&JUnitRunner{t: t, waiting: waiting})
+	Ref(&JUnitRunner{t: t, waiting: waiting, exit: Exit})
 	Run(configLocation...)
 }
@@ -17,37 +17,23 @@
 package boot
 
 import (
-	"github.com/go-spring/spring-core/bean"
-	"github.com/go-spring/spring-core/core"
-	"github.com/go-spring/spring-core/mq"
+	"github.com/go-spring/spring-core/app"
 )
 
 // ConditionalBindConsumer 为 BindConsumer 添加条件功能
-type ConditionalBindConsumer struct {
-	*mq.BindConsumer
-	cond bean.Condition // 判断条件
-}
+type ConditionalBindConsumer = app.ConditionalBindConsumer
 
-// WithCondition 设置一个 Condition
-func (c *ConditionalBindConsumer) WithCondition(cond bean.Condition) *ConditionalBindConsumer {
-	c.cond = cond
-	return c
-}
+// BindConsumerMapping 默认应用中以 BIND 形式注册的消息消费者的映射表
+//
+// Deprecated: 请使用 Default().BindConsumers()
+var BindConsumerMapping = gApp.BindConsumers()
 
-// CheckCondition 成功返回 true，失败返回 false
-func (c *ConditionalBindConsumer) CheckCondition(ctx core.ApplicationContext) bool {
-	if c.cond == nil {
-		return true
-	}
-	return c.cond.Matches(ctx)
+// BindConsumers 返回默认应用中以 BIND 形式注册的消息消费者的映射表
+func BindConsumers() map[string]*ConditionalBindConsumer {
+	return gApp.BindConsumers()
 }
 
-// BindConsumerMapping 以 BIND 形式注册的消息消费者的映射表
-var BindConsumerMapping = map[string]*ConditionalBindConsumer{}
-
 // BindConsumer 注册 BIND 形式的消息消费者
 func BindConsumer(topic string, fn interface{}) *ConditionalBindConsumer {
-	c := &ConditionalBindConsumer{BindConsumer: mq.BIND(topic, fn)}
-	BindConsumerMapping[topic] = c
-	return c
+	return gApp.BindConsumer(topic, fn)
 }
@@ -0,0 +1,123 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package boot
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/go-spring/spring-core/app"
+	"github.com/go-spring/spring-core/bean"
+)
+
+// TestApplication 测试用的应用，每个测试用例独立创建，不依赖 boot 包的全局
+// 应用，因此多个测试用例之间互不影响，可以并行执行。
+type TestApplication struct {
+	*app.Application
+	t *testing.T
+}
+
+// NewTestApplication TestApplication 的构造函数，测试应用不解析测试进程的命令行参数，
+// 只加载 SPRING_ 开头的环境变量，可以通过 SetArgs 和 ExpectSysProperties 修改。
+func NewTestApplication(t *testing.T, configLocation ...string) *TestApplication {
+	a := app.NewApplication()
+	a.SetBannerMode(app.BannerModeOff)
+	a.SetArgs([]string{}...)
+	a.ExpectSysProperties("^SPRING_")
+	a.AddConfigLocation(configLocation...)
+	a.Property("application-event.collection", "[]?")
+	a.Property("command-line-runner.collection", "[]?")
+	return &TestApplication{Application: a, t: t}
+}
+
+// MockBean 使用 mock 替换 selector 选中的 Bean，mock 需要和被替换
+// 的 Bean 类型相同或者实现了被替换的 Bean 导出的接口。
+func (a *TestApplication) MockBean(selector bean.BeanSelector, mock interface{}) *bean.BeanDefinition {
+	bd := bean.Ref(mock)
+	a.ReplaceBean(selector, bd)
+	return bd
+}
+
+// Spy 监视 selector 选中的 Bean 的方法调用，只有按照接口类型注入的 Bean
+// 才能被监视，并且该接口类型需要通过 bean.RegisterProxyFactory 注册代理工厂。
+func (a *TestApplication) Spy(selector bean.BeanSelector) *Spy {
+	s := &Spy{}
+	a.Intercept(bean.InterceptorFunc(s.invoke)).On(selector)
+	return s
+}
+
+// Start 启动应用，启动失败时终止测试用例，测试用例结束前需要调用 Stop 关闭应用。
+func (a *TestApplication) Start() *TestApplication {
+	defer func() {
+		if r := recover(); r != nil {
+			a.t.Fatalf("application start failed: %v", r)
+		}
+	}()
+	a.Application.Start()
+	return a
+}
+
+// SpyCall 一次被监视的方法调用
+type SpyCall struct {
+	Method  string
+	Args    []interface{}
+	Results []interface{}
+}
+
+// Spy 记录被监视的 Bean 的方法调用
+type Spy struct {
+	mutex sync.Mutex
+	calls []SpyCall
+}
+
+func (s *Spy) invoke(inv *bean.Invocation) []reflect.Value {
+	out := inv.Proceed()
+
+	call := SpyCall{Method: inv.Method}
+	for _, arg := range inv.Args {
+		call.Args = append(call.Args, arg.Interface())
+	}
+	for _, r := range out {
+		call.Results = append(call.Results, r.Interface())
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.calls = append(s.calls, call)
+	return out
+}
+
+// Calls 返回所有的方法调用记录
+func (s *Spy) Calls() []SpyCall {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]SpyCall{}, s.calls...)
+}
+
+// Count 返回指定方法的调用次数
+func (s *Spy) Count(method string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	n := 0
+	for _, c := range s.calls {
+		if c.Method == method {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package boot_test
+
+import (
+	"testing"
+
+	"github.com/go-spring/spring-core/bean"
+	"github.com/go-spring/spring-core/boot"
+	"github.com/go-spring/spring-core/util"
+)
+
+type Store interface {
+	Load(key string) string
+}
+
+type redisStore struct {
+	Addr string `value:"${redis.addr:=127.0.0.1:6379}"`
+}
+
+func (s *redisStore) Load(key string) string {
+	return s.Addr + "/" + key
+}
+
+type mockStore struct{}
+
+func (s *mockStore) Load(key string) string {
+	return "mock/" + key
+}
+
+type storeProxy struct {
+	h bean.InvocationHandler
+}
+
+func (p *storeProxy) Load(key string) string {
+	return p.h.Invoke("Load", key)[0].(string)
+}
+
+func init() {
+	bean.RegisterProxyFactory(func(h bean.InvocationHandler) Store {
+		return &storeProxy{h}
+	})
+}
+
+type StoreService struct {
+	Store Store `autowire:""`
+}
+
+func TestTestApplication(t *testing.T) {
+
+	t.Run("property", func(t *testing.T) {
+		t.Parallel()
+
+		a := boot.NewTestApplication(t)
+		a.Property("redis.addr", "10.0.0.1:6379")
+		a.Property("test.path", "${PATH:=isolated}")
+		a.RegisterBean(bean.Ref(new(redisStore)).Export((*Store)(nil)))
+		s := new(StoreService)
+		a.RegisterBean(bean.Ref(s))
+		a.Start()
+		defer a.Stop()
+
+		util.AssertEqual(t, s.Store.Load("k"), "10.0.0.1:6379/k")
+
+		// 不解析测试进程的命令行参数，不加载无关的环境变量
+		util.AssertEqual(t, len(a.Args()), 0)
+		util.AssertEqual(t, a.GetProperty("test.v"), nil)
+		util.AssertEqual(t, a.GetProperty("path"), nil)
+		util.AssertEqual(t, a.GetProperty("test.path"), "isolated")
+	})
+
+	t.Run("mock", func(t *testing.T) {
+		t.Parallel()
+
+		a := boot.NewTestApplication(t)
+		a.RegisterBean(bean.Ref(new(redisStore)).Export((*Store)(nil)))
+		a.MockBean((*redisStore)(nil), new(mockStore))
+		s := new(StoreService)
+		a.RegisterBean(bean.Ref(s))
+		a.Start()
+		defer a.Stop()
+
+		util.AssertEqual(t, s.Store.Load("k"), "mock/k")
+	})
+
+	t.Run("spy", func(t *testing.T) {
+		t.Parallel()
+
+		a := boot.NewTestApplication(t)
+		a.RegisterBean(bean.Ref(new(redisStore)).Export((*Store)(nil)))
+		spy := a.Spy((*redisStore)(nil))
+		s := new(StoreService)
+		a.RegisterBean(bean.Ref(s))
+		a.Start()
+		defer a.Stop()
+
+		util.AssertEqual(t, s.Store.Load("k"), "127.0.0.1:6379/k")
+		util.AssertEqual(t, spy.Count("Load"), 1)
+		util.AssertEqual(t, spy.Calls(), []boot.SpyCall{
+			{Method: "Load", Args: []interface{}{"k"}, Results: []interface{}{"127.0.0.1:6379/k"}},
+		})
+	})
+}
@@ -27,22 +27,28 @@ func TestRouter_Route(t *testing.T) {
 	root := Route("/root", FilterBean("r1", "r2")).WithCondition(cond.OnBean("r"))
 
 	get := root.GetMapping("/get", nil, FilterBean("g1", "g2")).WithCondition(cond.OnBean("g"))
-	util.AssertEqual(t, get, DefaultWebMapping.Mappings[get.Key()])
+	util.AssertEqual(t, get, gApp.WebMapping().Mappings[get.Key()])
 	util.AssertEqual(t, get.Path(), "/root/get")
 	util.AssertEqual(t, len(get.Filters()), 2)
 	// TODO 校验 cond 字段是否正确
 
 	sub := root.Route("/sub", FilterBean("s1", "s2")).WithCondition(cond.OnBean("s"))
 	subGet := sub.GetMapping("/get", nil, FilterBean("sg1", "sg2")).WithCondition(cond.OnBean("sg"))
-	util.AssertEqual(t, subGet, DefaultWebMapping.Mappings[subGet.Key()])
+	util.AssertEqual(t, subGet, gApp.WebMapping().Mappings[subGet.Key()])
 	util.AssertEqual(t, subGet.Path(), "/root/sub/get")
 	util.AssertEqual(t, len(subGet.Filters()), 3)
 	// ...
 
 	subSub := sub.Route("/sub", FilterBean("ss1", "ss2")).WithCondition(cond.OnBean("ss"))
 	subSubGet := subSub.GetMapping("/get", nil, FilterBean("ssg1", "ssg2")).WithCondition(cond.OnBean("ssg"))
-	util.AssertEqual(t, subSubGet, DefaultWebMapping.Mappings[subSubGet.Key()])
+	util.AssertEqual(t, subSubGet, gApp.WebMapping().Mappings[subSubGet.Key()])
 	util.AssertEqual(t, subSubGet.Path(), "/root/sub/sub/get")
 	util.AssertEqual(t, len(subSubGet.Filters()), 4)
 	// ...
 }
+
+func TestDefaultWebMapping(t *testing.T) {
+	m := GetMapping("/deprecated", nil)
+	util.AssertEqual(t, m, DefaultWebMapping.Mappings[m.Key()])
+	util.AssertEqual(t, DefaultWebMapping, Default().WebMapping())
+}
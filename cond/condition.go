@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	"github.com/go-spring/spring-core/bean"
+	"github.com/go-spring/spring-core/conf"
 	"github.com/go-spring/spring-core/util"
 	"github.com/spf13/cast"
 )
@@ -72,6 +73,7 @@ func PropertyCondition(name string) *propertyCondition {
 
 // Matches 成功返回 true，失败返回 false
 func (c *propertyCondition) Matches(ctx bean.ConditionContext) bool {
+	ctx.Metadata().Register(conf.PropertyMeta{Key: c.name})
 	return len(ctx.Properties().Prefix(c.name)) > 0
 }
 
@@ -87,6 +89,7 @@ func MissingPropertyCondition(name string) *missingPropertyCondition {
 
 // Matches 成功返回 true，失败返回 false
 func (c *missingPropertyCondition) Matches(ctx bean.ConditionContext) bool {
+	ctx.Metadata().Register(conf.PropertyMeta{Key: c.name})
 	return len(ctx.Properties().Prefix(c.name)) == 0
 }
 
@@ -121,6 +124,7 @@ func PropertyValueCondition(name string, havingValue interface{},
 func (c *propertyValueCondition) Matches(ctx bean.ConditionContext) bool {
 	// 参考 /usr/local/go/src/go/types/eval_test.go 示例
 
+	ctx.Metadata().Register(conf.PropertyMeta{Key: c.name})
 	val := ctx.Properties().Get(c.name)
 	if val == nil { // 不存在返回默认值
 		return c.matchIfMissing
@@ -195,16 +199,33 @@ func (c *expressionCondition) Matches(ctx bean.ConditionContext) bool {
 // profileCondition 基于运行环境匹配的 Condition 实现
 type profileCondition struct {
 	profile string
+	expr    profileExpr
 }
 
-// ProfileCondition profileCondition 的构造函数
+// ProfileCondition profileCondition 的构造函数，profile 可以是运行环境表达式，
+// 例如 "!dev & (cn | us)"，表达式有语法错误时 panic，为空时总是匹配。
 func ProfileCondition(profile string) *profileCondition {
-	return &profileCondition{profile}
+	c := &profileCondition{profile: profile}
+	if strings.TrimSpace(profile) != "" {
+		expr, err := parseProfileExpr(profile)
+		if err != nil {
+			panic(err)
+		}
+		c.expr = expr
+	}
+	return c
 }
 
 // Matches 成功返回 true，失败返回 false
 func (c *profileCondition) Matches(ctx bean.ConditionContext) bool {
-	return c.profile == "" || strings.EqualFold(c.profile, ctx.GetProfile())
+	if c.expr == nil {
+		return true
+	}
+	active := make(map[string]bool)
+	for _, profile := range ctx.GetProfiles() {
+		active[strings.ToLower(profile)] = true
+	}
+	return c.expr(active)
 }
 
 // ConditionOp conditionNode 的计算方式
@@ -225,3 +225,33 @@ func TestNotCondition(t *testing.T) {
 		OnConditionNot(profileCond)
 	util.AssertEqual(t, c.Matches(ctx), false)
 }
+
+func TestProfileCondition(t *testing.T) {
+
+	ctx := core.NewApplicationContext()
+	ctx.Profile("prod, CN")
+	ctx.AutoWireBeans()
+
+	util.AssertEqual(t, ctx.GetProfiles(), []string{"prod", "CN"})
+
+	for expr, expect := range map[string]bool{
+		"":                  true,
+		"prod":              true,
+		"cn":                true,
+		"dev":               false,
+		"dev,prod":          true,
+		"!dev":              true,
+		"!dev & (cn | us)":  true,
+		"!dev & (jp | us)":  false,
+		"prod & !cn":        false,
+		"!(dev | test)":     true,
+		"dev | prod & cn":   true,
+		"(dev | prod) & us": false,
+	} {
+		util.AssertEqual(t, cond.ProfileCondition(expr).Matches(ctx), expect)
+	}
+
+	util.AssertPanic(t, func() { cond.ProfileCondition("dev & (cn | us") }, `missing \)`)
+	util.AssertPanic(t, func() { cond.ProfileCondition("dev &") }, "missing profile")
+	util.AssertPanic(t, func() { cond.ProfileCondition("dev us") }, `unexpected 'u'`)
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cond
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// profileExpr 运行环境表达式，参数是小写的激活的运行环境集合。
+type profileExpr func(active map[string]bool) bool
+
+// profileParser 运行环境表达式的解析器，语法如下，优先级从低到高:
+//
+//	expr    = and { ("|" | ",") and }
+//	and     = unary { "&" unary }
+//	unary   = "!" unary | "(" expr ")" | profile
+//
+// 例如 "!dev & (cn | us)"，逗号和 | 的含义相同，以兼容 "dev,test" 的写法。
+type profileParser struct {
+	s   string
+	pos int
+}
+
+// parseProfileExpr 解析运行环境表达式
+func parseProfileExpr(s string) (profileExpr, error) {
+	p := &profileParser{s: s}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.s) {
+		return nil, p.errorf("unexpected %q", p.s[p.pos])
+	}
+	return expr, nil
+}
+
+func (p *profileParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid profile expression %q at %d: %s", p.s, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *profileParser) skipSpace() {
+	for p.pos < len(p.s) && unicode.IsSpace(rune(p.s[p.pos])) {
+		p.pos++
+	}
+}
+
+// accept 跳过空白后如果下一个字符在 chars 中则消费它
+func (p *profileParser) accept(chars string) bool {
+	if p.skipSpace(); p.pos < len(p.s) && strings.IndexByte(chars, p.s[p.pos]) >= 0 {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *profileParser) parseOr() (profileExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("|,") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(active map[string]bool) bool { return l(active) || right(active) }
+	}
+	return left, nil
+}
+
+func (p *profileParser) parseAnd() (profileExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(active map[string]bool) bool { return l(active) && right(active) }
+	}
+	return left, nil
+}
+
+func (p *profileParser) parseUnary() (profileExpr, error) {
+
+	if p.accept("!") {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(active map[string]bool) bool { return !expr(active) }, nil
+	}
+
+	if p.accept("(") {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.errorf("missing )")
+		}
+		return expr, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if unicode.IsSpace(rune(c)) || strings.IndexByte("!&|,()", c) >= 0 {
+			break
+		}
+		p.pos++
+	}
+
+	if p.pos == start {
+		return nil, p.errorf("missing profile")
+	}
+
+	profile := strings.ToLower(p.s[start:p.pos])
+	return func(active map[string]bool) bool { return active[profile] }, nil
+}
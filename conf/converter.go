@@ -0,0 +1,117 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// timeType time.Time 的反射类型
+var timeType = reflect.TypeOf(time.Time{})
+
+// ByteSize 字节数，绑定属性值时支持 10MB 这样带单位的形式，参见 ParseSize。
+type ByteSize int64
+
+// parseByteSize string -> ByteSize converter
+func parseByteSize(s string) (ByteSize, error) {
+	n, err := ParseSize(s)
+	return ByteSize(n), err
+}
+
+// parseIP string -> net.IP converter
+func parseIP(s string) (net.IP, error) {
+	if ip := net.ParseIP(s); ip != nil {
+		return ip, nil
+	}
+	return nil, fmt.Errorf("invalid ip %q", s)
+}
+
+// parseURL string -> *url.URL converter
+func parseURL(s string) (*url.URL, error) {
+	return url.Parse(s)
+}
+
+// parseRegexp string -> *regexp.Regexp converter
+func parseRegexp(s string) (*regexp.Regexp, error) {
+	return regexp.Compile(s)
+}
+
+// hasConverter 返回 t 类型的属性值是否使用类型转换器进行转换，指定了 layout 的
+// time.Time 按照 layout 进行解析。
+func hasConverter(p Properties, t reflect.Type, opt BindOption) bool {
+	if t == timeType && opt.Layout != "" {
+		return true
+	}
+	fn, _ := converterOf(p, t)
+	return fn != nil
+}
+
+// converterOf 返回 t 类型的类型转换器，结构体类型没有转换器时使用它的指针类型的转换器，
+// 这时 deref 为 true，例如 regexp.Regexp 和 url.URL 类型的字段。
+func converterOf(p Properties, t reflect.Type) (fn interface{}, deref bool) {
+	if fn, ok := p.Converters()[t]; ok {
+		return fn, false
+	}
+	if t.Kind() == reflect.Struct {
+		if fn, ok := p.Converters()[reflect.PtrTo(t)]; ok {
+			return fn, true
+		}
+	}
+	return nil, false
+}
+
+// convertValue 使用类型转换器将属性值转换成 t 类型，调用前需要使用 hasConverter 检查。
+func convertValue(p Properties, t reflect.Type, val interface{}, opt BindOption) (reflect.Value, error) {
+
+	// 属性值已经是目标类型，例如 yaml 中的时间
+	if reflect.TypeOf(val) == t {
+		return reflect.ValueOf(val), nil
+	}
+
+	s, err := cast.ToStringE(val)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("property value %s isn't string type", opt.FullName)
+	}
+
+	if t == timeType && opt.Layout != "" {
+		tm, err := time.Parse(opt.Layout, s)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("property value %s: %v", opt.FullName, err)
+		}
+		return reflect.ValueOf(tm), nil
+	}
+
+	fn, deref := converterOf(p, t)
+	out := reflect.ValueOf(fn).Call([]reflect.Value{reflect.ValueOf(s)})
+	if err, _ = out[1].Interface().(error); err != nil {
+		return reflect.Value{}, fmt.Errorf("property value %s: %v", opt.FullName, err)
+	}
+	if deref {
+		if out[0].IsNil() {
+			return reflect.Value{}, fmt.Errorf("property value %s: converter returns nil", opt.FullName)
+		}
+		return out[0].Elem(), nil
+	}
+	return out[0], nil
+}
@@ -0,0 +1,38 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"reflect"
+)
+
+// Diff 比较两组属性值，返回新增和修改的属性及其新值，删除的属性的值为 nil，
+// 结果可以直接传给 Properties.Set 或者 ApplicationContext.RefreshProperties。
+func Diff(oldProperties, newProperties map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	for k, v := range newProperties {
+		if oldValue, ok := oldProperties[k]; !ok || !reflect.DeepEqual(oldValue, v) {
+			result[k] = v
+		}
+	}
+	for k := range oldProperties {
+		if _, ok := newProperties[k]; !ok {
+			result[k] = nil
+		}
+	}
+	return result
+}
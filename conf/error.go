@@ -0,0 +1,67 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BindError 属性绑定错误，记录了出错的属性名以及属性值的来源。
+type BindError struct {
+	Key    string  // 属性名
+	Origin *Origin // 属性值的来源，例如配置文件、环境变量和命令行参数
+	Err    error
+}
+
+func (e *BindError) Error() string {
+	if e.Origin == nil {
+		return fmt.Sprintf("%v (key: %s)", e.Err, e.Key)
+	}
+	return fmt.Sprintf("%v (key: %s, origin: %s)", e.Err, e.Key, e.Origin)
+}
+
+// BindErrors 绑定结构体时收集到的多个错误
+type BindErrors []error
+
+func (e BindErrors) Error() string {
+	s := make([]string, len(e))
+	for i, err := range e {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+// appendError 添加一个错误，多个错误会被展开。
+func appendError(errs BindErrors, err error) BindErrors {
+	if e, ok := err.(BindErrors); ok {
+		return append(errs, e...)
+	}
+	return append(errs, err)
+}
+
+// toError 没有错误时返回 nil，只有一个错误时返回该错误本身。
+func (e BindErrors) toError() error {
+	switch len(e) {
+	case 0:
+		return nil
+	case 1:
+		return e[0]
+	default:
+		return e
+	}
+}
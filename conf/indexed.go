@@ -0,0 +1,134 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// parseIndexedKey 解析 servers[0].host 形式的属性名，返回 key 之后的索引和剩余部分，
+// 剩余部分为空时表示属性值就是元素本身，不是 key 的索引形式时返回 false。
+func parseIndexedKey(s string, key string) (index int, rest string, ok bool) {
+
+	i := strings.Index(s, "[")
+	if i <= 0 || CanonicalKey(s[:i]) != CanonicalKey(key) {
+		return 0, "", false
+	}
+
+	j := strings.Index(s[i:], "]")
+	if j < 0 {
+		return 0, "", false
+	}
+
+	index, err := strconv.Atoi(s[i+1 : i+j])
+	if err != nil || index < 0 {
+		return 0, "", false
+	}
+
+	switch rest = s[i+j+1:]; {
+	case rest == "":
+		return index, "", true
+	case strings.HasPrefix(rest, "."):
+		return index, rest[1:], true
+	case strings.HasPrefix(rest, "["): // 多维数组
+		return index, rest, true
+	}
+	return 0, "", false
+}
+
+// getIndexedValue 收集 key[0]、key[1].name 等索引形式的属性值，每个元素要么
+// 是属性值本身，要么是以剩余部分为键的 map，索引必须从 0 开始并且连续。每个属性
+// 值按照各自的属性名解析引用，参见 resolveStored。
+func getIndexedValue(p Properties, key string) ([]interface{}, error) {
+
+	var result []interface{}
+	for _, k := range p.Keys() {
+
+		index, rest, ok := parseIndexedKey(k, key)
+		if !ok {
+			continue
+		}
+
+		v, err := resolveStored(p, k, p.Get(k))
+		if err != nil {
+			return nil, err
+		}
+
+		for len(result) <= index {
+			result = append(result, nil)
+		}
+
+		if rest == "" {
+			result[index] = v
+			continue
+		}
+
+		item, ok := result[index].(map[string]interface{})
+		if !ok {
+			if result[index] != nil {
+				return nil, fmt.Errorf("property %s[%d] is both value and object", key, index)
+			}
+			item = make(map[string]interface{})
+			result[index] = item
+		}
+		item[rest] = v
+	}
+
+	for i, v := range result {
+		if v == nil {
+			return nil, fmt.Errorf("property %s[%d] not config", key, i)
+		}
+	}
+	return result, nil
+}
+
+// flattenMap 将嵌套的 map 展开成多级属性名，例如 {"tls": {"enabled": true}}
+// 展开成 {"tls.enabled": true}，数组保持原样以便按照数组进行绑定。
+func flattenMap(prefix string, m map[string]interface{}, result map[string]interface{}) {
+	for k, v := range m {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+		switch v.(type) {
+		case map[string]interface{}, map[interface{}]interface{}:
+			flattenMap(k, cast.ToStringMap(v), result)
+		default:
+			result[k] = v
+		}
+	}
+}
+
+// subProperties 使用 m 创建一个临时的属性列表用于绑定数组或者 map 的元素，
+// 嵌套的 map 会被展开成多级属性名，并且沿用 p 的类型转换器和环境变量。
+func subProperties(p Properties, m map[string]interface{}) *defaultProperties {
+	flat := make(map[string]interface{})
+	flattenMap("", m, flat)
+	sub := &defaultProperties{
+		properties: make(map[string]interface{}),
+		converters: p.Converters(),
+		relaxed:    make(map[string]string),
+		env:        p.Env(),
+	}
+	for _, k := range sortedKeys(flat) {
+		sub.Set(k, flat[k])
+	}
+	return sub
+}
@@ -0,0 +1,183 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// PropertyMeta 属性的元数据，用于生成命令行帮助信息和配置文档。
+type PropertyMeta struct {
+	Key         string   `json:"key"`                   // 属性名
+	Type        string   `json:"type,omitempty"`        // 属性值的类型，例如 string、int、bool
+	Default     string   `json:"default,omitempty"`     // 默认值，没有默认值时为空
+	Description string   `json:"description,omitempty"` // 属性的描述
+	Owners      []string `json:"owners,omitempty"`      // 使用该属性的 Bean
+}
+
+// Metadata 属性元数据的集合，属性名按照规范形式进行匹配，参见 CanonicalKey。
+type Metadata struct {
+	mutex sync.RWMutex
+	metas map[string]*PropertyMeta
+}
+
+// NewMetadata Metadata 的构造函数
+func NewMetadata() *Metadata {
+	return &Metadata{metas: make(map[string]*PropertyMeta)}
+}
+
+// Register 注册属性的元数据，同一个属性多次注册时合并非空的字段，先注册的字段优先，
+// 使用该属性的 Bean 会被合并到一起并且按照名称排序。
+func (m *Metadata) Register(meta PropertyMeta) *Metadata {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := CanonicalKey(meta.Key)
+	old, ok := m.metas[key]
+	if !ok {
+		old = &PropertyMeta{Key: meta.Key}
+		m.metas[key] = old
+	}
+
+	for _, owner := range meta.Owners {
+		if !containsString(old.Owners, owner) {
+			old.Owners = append(old.Owners, owner)
+		}
+	}
+	sort.Strings(old.Owners)
+
+	if old.Type == "" {
+		old.Type = meta.Type
+	}
+	if old.Default == "" {
+		old.Default = meta.Default
+	}
+	if old.Description == "" {
+		old.Description = meta.Description
+	}
+	return m
+}
+
+// Get 返回属性的元数据，没有注册时返回 false。
+func (m *Metadata) Get(key string) (PropertyMeta, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	if meta, ok := m.metas[CanonicalKey(key)]; ok {
+		return meta.copy(), true
+	}
+	return PropertyMeta{}, false
+}
+
+// Known 返回属性是否被使用，属性本身或者它的上级属性注册过元数据都算被使用，
+// 例如注册了 servers 时 servers[0].host 和 servers.a 都被使用。
+func (m *Metadata) Known(key string) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	ck := CanonicalKey(key)
+	for mk := range m.metas {
+		if ck == mk || strings.HasPrefix(ck, mk+".") || strings.HasPrefix(ck, mk+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// All 返回按照属性名排序的所有元数据
+func (m *Metadata) All() []PropertyMeta {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	result := make([]PropertyMeta, 0, len(m.metas))
+	for _, meta := range m.metas {
+		result = append(result, meta.copy())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// WriteJSON 以 JSON 数组的格式输出所有元数据
+func (m *Metadata) WriteJSON(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(m.All())
+}
+
+// WriteMarkdown 以 Markdown 表格的格式输出所有元数据，可以作为配置项的参考文档。
+func (m *Metadata) WriteMarkdown(w io.Writer) error {
+	escape := strings.NewReplacer("|", "\\|", "\n", " ").Replace
+	lines := []string{
+		"| Key | Type | Default | Description | Owners |",
+		"| --- | --- | --- | --- | --- |",
+	}
+	for _, meta := range m.All() {
+		owners := make([]string, len(meta.Owners))
+		for i, owner := range meta.Owners {
+			owners[i] = "`" + owner + "`"
+		}
+		lines = append(lines, fmt.Sprintf("| `%s` | %s | %s | %s | %s |",
+			meta.Key, escape(meta.Type), escape(meta.Default),
+			escape(meta.Description), strings.Join(owners, "<br>")))
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// CollectMetadata 收集结构体类型 t 中 value 标签以及 prefix 前缀下的字段的元数据，
+// prefix 为空时只收集 value 标签。收集时不需要属性值，因此可以在加载配置之前使用，
+// 例如输出命令行帮助信息和识别布尔类型的选项。实现上是使用空的属性列表对 t 的零值
+// 进行一次绑定，绑定过程会记录元数据，绑定的错误被忽略，p 只提供类型转换器。
+func CollectMetadata(p Properties, t reflect.Type, prefix string, opt BindOption) {
+	if opt.Metadata == nil || t.Kind() != reflect.Struct {
+		return
+	}
+
+	empty := New()
+	empty.SetEnv(nil)
+	for _, fn := range p.Converters() {
+		_ = empty.Convert(fn)
+	}
+
+	v := reflect.New(t).Elem()
+	_ = BindStruct(empty, v, opt)
+	if prefix != "" {
+		_, _, _ = BindPrefix(empty, v, prefix, opt)
+	}
+}
+
+// copy 返回元数据的拷贝，以免调用者修改 Owners
+func (meta *PropertyMeta) copy() PropertyMeta {
+	c := *meta
+	c.Owners = append([]string(nil), meta.Owners...)
+	return c
+}
+
+// containsString 返回 ss 中是否包含 s
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
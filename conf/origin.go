@@ -0,0 +1,101 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Origin 属性值的来源
+type Origin struct {
+	Layer     string // 属性层的名称，例如 cmd-args、sys-env、app-config
+	File      string // 配置文件的路径，不是来自文件时为空
+	Line      int    // 属性在配置文件中的行号，无法确定时为 0
+	EnvVar    string // 环境变量的名称，不是来自环境变量时为空
+	Sensitive bool   // 是否是敏感信息，敏感信息输出时需要脱敏
+	Resolved  bool   // 属性值中的引用是否已经解析过，解析过的属性值读取时不再解析
+}
+
+// String 返回来源的描述，例如 app-config config/application.properties:3
+func (o *Origin) String() string {
+	if o == nil {
+		return ""
+	}
+	s := o.Layer
+	if o.File != "" {
+		s += " " + o.File
+		if o.Line > 0 {
+			s += fmt.Sprintf(":%d", o.Line)
+		}
+	}
+	if o.EnvVar != "" {
+		s += " " + o.EnvVar
+	}
+	return strings.TrimSpace(s)
+}
+
+// MaskedValue 脱敏后的属性值
+const MaskedValue = "******"
+
+// sensitiveKey 属性名中以点号、下划线、短横线或者方括号分隔的某一段是这些单词时被当作
+// 敏感信息，例如 db.password、anthropic_api_key，而 max_context_tokens 不是。
+var sensitiveKey = regexp.MustCompile(`(?i)(^|[._\-\[\]])(password|passwd|secrets?|token|credentials?|` +
+	`(private|api|access)[._-]?key)($|[._\-\[\]])`)
+
+// IsSensitive 返回属性是否是敏感信息，来源标记为敏感或者属性名看起来是密码、密钥等。
+func IsSensitive(p Properties, key string) bool {
+	if o := p.Origin(key); o != nil && o.Sensitive {
+		return true
+	}
+	return sensitiveKey.MatchString(key)
+}
+
+// MaskValue 返回用于输出的属性值，敏感信息返回 MaskedValue，用于日志等场景。
+func MaskValue(p Properties, key string, value interface{}) interface{} {
+	if IsSensitive(p, key) {
+		return MaskedValue
+	}
+	return value
+}
+
+// Dump 按照属性名的顺序输出所有的属性值及其来源，敏感信息会被脱敏。
+func Dump(p Properties, w io.Writer) error {
+
+	m := make(map[string]interface{})
+	p.Fill(m)
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		line := fmt.Sprintf("%s=%v", k, MaskValue(p, k, m[k]))
+		if o := p.Origin(k); o != nil {
+			line += " # " + o.String()
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/go-spring/spring-core/util"
+)
+
+// kebabCase 将字段名转换成短横线分隔的小写形式，例如 MaxIdleConns 转换成
+// max-idle-conns，URLPath 转换成 url-path。
+func kebabCase(name string) string {
+	rs := []rune(name)
+	var sb strings.Builder
+	for i, r := range rs {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := rs[i-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				sb.WriteByte('-')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
+
+// hasProperty 返回 key 本身、以 key 为前缀或者 key[i] 形式的属性是否存在。
+func hasProperty(p Properties, key string) bool {
+	if p.Has(key) || len(p.Prefix(key)) > 0 {
+		return true
+	}
+	indexedValue, err := getIndexedValue(p, key)
+	return err != nil || len(indexedValue) > 0
+}
+
+// coversKey 返回属性 k 是否属于 key 对应的字段，包括 key 的子属性和索引形式的属性。
+func coversKey(k string, key string) bool {
+	return hasKeyPrefix(k, key) || strings.HasPrefix(CanonicalKey(k), CanonicalKey(key)+"[")
+}
+
+// PrefixField 按照前缀绑定的结构体字段，参见 PrefixFields。
+type PrefixField struct {
+	Key     string        // 字段对应的属性名
+	Value   reflect.Value // 字段的值，私有的匿名嵌套结构体中的字段已经开放
+	Opt     BindOption    // 字段的绑定可选项
+	Missing bool          // 字段对应的属性是否没有配置
+}
+
+// PrefixFields 返回 BindPrefix 按照前缀绑定的字段，也就是没有 value、autowire 和
+// inject 标签的导出字段，嵌套的结构体展开成它的字段，其他字段不会出现在结果中。
+func PrefixFields(p Properties, v reflect.Value, prefix string, opt BindOption) []PrefixField {
+
+	var fields []PrefixField
+
+	var collect func(v reflect.Value, prefix string, fieldName string)
+	collect = func(v reflect.Value, prefix string, fieldName string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			ft := t.Field(i)
+			fv := util.PatchValue(v.Field(i), true)
+
+			if ft.PkgPath != "" && !ft.Anonymous {
+				continue // 只绑定导出字段
+			}
+			if _, ok := ft.Tag.Lookup("value"); ok {
+				continue
+			}
+			if _, ok := ft.Tag.Lookup("autowire"); ok {
+				continue
+			}
+			if _, ok := ft.Tag.Lookup("inject"); ok {
+				continue
+			}
+
+			subOpt := BindOption{
+				FieldName: fieldName + ".$" + ft.Name,
+				Validate:  ft.Tag.Get("validate"),
+				Metadata:  opt.Metadata,
+				Owner:     opt.Owner,
+				Layout:    ft.Tag.Get("layout"),
+			}
+
+			isStruct := ft.Type.Kind() == reflect.Struct && !hasConverter(p, ft.Type, subOpt)
+			if ft.Anonymous {
+				if isStruct {
+					collect(fv, prefix, subOpt.FieldName)
+				}
+				continue
+			}
+
+			key := kebabCase(ft.Name)
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+
+			if isStruct {
+				collect(fv, key, subOpt.FieldName)
+				continue
+			}
+
+			fields = append(fields, PrefixField{
+				Key:     key,
+				Value:   fv,
+				Opt:     subOpt,
+				Missing: !hasProperty(p, key),
+			})
+		}
+	}
+	collect(v, prefix, opt.FieldName)
+	return fields
+}
+
+// BindPrefix 按照宽松的名称将 prefix 下的属性绑定到结构体 v 的导出字段上，字段名
+// 转换成短横线分隔的形式作为属性名，例如 MaxIdleConns 对应 prefix.max-idle-conns，
+// 嵌套的结构体按照层级绑定，匿名嵌套的结构体直接展开。使用 value、autowire 或者
+// inject 标签的字段由原来的方式处理，这里不会绑定。返回 prefix 下没有对应字段的
+// 属性名，以及没有配置的字段对应的属性名，没有配置的字段保持原来的值。
+func BindPrefix(p Properties, v reflect.Value, prefix string, opt BindOption) (unknown []string, missing []string, err error) {
+
+	var (
+		errs     BindErrors
+		consumed []string
+	)
+
+	for _, f := range PrefixFields(p, v, prefix, opt) {
+		if f.Missing {
+			missing = append(missing, f.Key)
+			if opt.Metadata != nil {
+				meta := PropertyMeta{Key: f.Key, Type: f.Value.Type().String()}
+				if opt.Owner != "" {
+					meta.Owners = []string{opt.Owner}
+				}
+				opt.Metadata.Register(meta)
+			}
+			continue
+		}
+
+		consumed = append(consumed, f.Key)
+		if err := BindStructField(p, f.Value, "${"+f.Key+"}", f.Opt); err != nil {
+			errs = appendError(errs, err)
+		}
+	}
+
+	if prefix != "" {
+		for k := range p.Prefix(prefix) {
+			known := false
+			for _, key := range consumed {
+				if coversKey(k, key) {
+					known = true
+					break
+				}
+			}
+			if !known {
+				unknown = append(unknown, k)
+			}
+		}
+		sort.Strings(unknown)
+	}
+	return unknown, missing, errs.toError()
+}
@@ -17,7 +17,10 @@
 package conf
 
 import (
-	"github.com/go-spring/spring-core/util"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
 )
 
 // priorityProperties 基于优先级的 Properties 版本
@@ -37,9 +40,29 @@ func (p *priorityProperties) Has(key string) bool {
 	return p.Properties.Has(key) || p.next.Has(key)
 }
 
-// Bind 根据类型获取属性值，属性名称统一转成小写。
+// Origin 返回属性值的来源，即第一个存在该属性的属性层记录的来源。
+func (p *priorityProperties) Origin(key string) *Origin {
+	if p.Properties.Has(key) {
+		return p.Properties.Origin(key)
+	}
+	return p.next.Origin(key)
+}
+
+// Bind 根据类型获取属性值，属性名称统一转成小写，高优先级的属性值覆盖低优先级的。
 func (p *priorityProperties) Bind(key string, i interface{}) error {
-	panic(util.UnimplementedMethod)
+
+	v := reflect.ValueOf(i)
+	if v.Kind() != reflect.Ptr {
+		return errors.New("参数 v 必须是一个指针")
+	}
+
+	t := v.Type().Elem()
+	s := t.Name() // 当绑定对象是 map 或者 slice 时，取元素的类型名
+	if s == "" && (t.Kind() == reflect.Map || t.Kind() == reflect.Slice) {
+		s = t.Elem().Name()
+	}
+
+	return BindValue(p, v.Elem(), key, nil, BindOption{FieldName: s, FullName: key})
 }
 
 // Get 返回属性值，不能存在返回 nil，属性名称统一转成小写。
@@ -69,15 +92,135 @@ func (p *priorityProperties) GetDefault(key string, def interface{}) interface{}
 	}
 }
 
-// Keys 返回所有键，属性名称统一转成小写。
+// GetBool 返回布尔类型的属性值，属性不存在或者无法转换时返回错误。
+func (p *priorityProperties) GetBool(key string) (bool, error) {
+	return getBool(p, key)
+}
+
+// MustGetBool 返回布尔类型的属性值，属性不存在或者无法转换时 panic。
+func (p *priorityProperties) MustGetBool(key string) bool {
+	v, err := getBool(p, key)
+	mustValue(err)
+	return v
+}
+
+// GetInt 返回整数类型的属性值，属性不存在或者无法转换时返回错误。
+func (p *priorityProperties) GetInt(key string) (int64, error) {
+	return getInt(p, key)
+}
+
+// MustGetInt 返回整数类型的属性值，属性不存在或者无法转换时 panic。
+func (p *priorityProperties) MustGetInt(key string) int64 {
+	v, err := getInt(p, key)
+	mustValue(err)
+	return v
+}
+
+// GetUint 返回无符号整数类型的属性值，属性不存在或者无法转换时返回错误。
+func (p *priorityProperties) GetUint(key string) (uint64, error) {
+	return getUint(p, key)
+}
+
+// MustGetUint 返回无符号整数类型的属性值，属性不存在或者无法转换时 panic。
+func (p *priorityProperties) MustGetUint(key string) uint64 {
+	v, err := getUint(p, key)
+	mustValue(err)
+	return v
+}
+
+// GetFloat 返回浮点类型的属性值，属性不存在或者无法转换时返回错误。
+func (p *priorityProperties) GetFloat(key string) (float64, error) {
+	return getFloat(p, key)
+}
+
+// MustGetFloat 返回浮点类型的属性值，属性不存在或者无法转换时 panic。
+func (p *priorityProperties) MustGetFloat(key string) float64 {
+	v, err := getFloat(p, key)
+	mustValue(err)
+	return v
+}
+
+// GetString 返回字符串类型的属性值，属性不存在或者无法转换时返回错误。
+func (p *priorityProperties) GetString(key string) (string, error) {
+	return getString(p, key)
+}
+
+// MustGetString 返回字符串类型的属性值，属性不存在或者无法转换时 panic。
+func (p *priorityProperties) MustGetString(key string) string {
+	v, err := getString(p, key)
+	mustValue(err)
+	return v
+}
+
+// GetDuration 返回时长类型的属性值，属性不存在或者无法转换时返回错误。
+func (p *priorityProperties) GetDuration(key string) (time.Duration, error) {
+	return getDuration(p, key)
+}
+
+// MustGetDuration 返回时长类型的属性值，属性不存在或者无法转换时 panic。
+func (p *priorityProperties) MustGetDuration(key string) time.Duration {
+	v, err := getDuration(p, key)
+	mustValue(err)
+	return v
+}
+
+// GetTime 返回时间类型的属性值，属性不存在或者无法转换时返回错误。
+func (p *priorityProperties) GetTime(key string) (time.Time, error) {
+	return getTime(p, key)
+}
+
+// MustGetTime 返回时间类型的属性值，属性不存在或者无法转换时 panic。
+func (p *priorityProperties) MustGetTime(key string) time.Time {
+	v, err := getTime(p, key)
+	mustValue(err)
+	return v
+}
+
+// GetStringSlice 返回字符串切片类型的属性值，字符串按照逗号进行切割，属性不存在或者无法转换时返回错误。
+func (p *priorityProperties) GetStringSlice(key string) ([]string, error) {
+	return getStringSlice(p, key)
+}
+
+// MustGetStringSlice 返回字符串切片类型的属性值，属性不存在或者无法转换时 panic。
+func (p *priorityProperties) MustGetStringSlice(key string) []string {
+	v, err := getStringSlice(p, key)
+	mustValue(err)
+	return v
+}
+
+// GetSize 返回字节数的属性值，支持 10MB 这样带单位的形式，属性不存在或者无法转换时返回错误。
+func (p *priorityProperties) GetSize(key string) (int64, error) {
+	return getSize(p, key)
+}
+
+// MustGetSize 返回字节数的属性值，属性不存在或者无法转换时 panic。
+func (p *priorityProperties) MustGetSize(key string) int64 {
+	v, err := getSize(p, key)
+	mustValue(err)
+	return v
+}
+
+// Keys 返回所有键，属性名称统一转成小写，宽松匹配的键只返回高优先级的。
 func (p *priorityProperties) Keys() []string {
-	panic(util.UnimplementedMethod)
+	var keys []string
+	p.Range(func(key string, _ interface{}) {
+		keys = append(keys, key)
+	})
+	return keys
 }
 
-// Range 遍历所有的属性值，属性名称统一转成小写。
+// Range 遍历所有的属性值，属性名称统一转成小写，被高优先级覆盖的属性值不会遍历。
 func (p *priorityProperties) Range(fn func(string, interface{})) {
-	p.Properties.Range(fn)
-	p.next.Range(fn)
+	visited := make(map[string]bool)
+	p.Properties.Range(func(key string, val interface{}) {
+		visited[CanonicalKey(key)] = true
+		fn(key, val)
+	})
+	p.next.Range(func(key string, val interface{}) {
+		if !visited[CanonicalKey(key)] {
+			fn(key, val)
+		}
+	})
 }
 
 // Fill Fill 填充所有的属性值，属性名称统一转成小写。TODO 实现并不完美。
@@ -94,14 +237,35 @@ func (p *priorityProperties) Fill(properties map[string]interface{}) {
 	})
 }
 
-// Prefix 返回指定前缀的属性值集合，属性名称统一转成小写。
+// Prefix 返回指定前缀的属性值集合，属性名称统一转成小写，支持宽松匹配。
 func (p *priorityProperties) Prefix(key string) map[string]interface{} {
-	panic(util.UnimplementedMethod)
+	key = strings.ToLower(key)
+	result := make(map[string]interface{})
+	p.Range(func(k string, v interface{}) {
+		if hasKeyPrefix(k, key) {
+			result[k] = v
+		}
+	})
+	return result
 }
 
-// Group 返回指定前缀的属性值集合并进行分组，属性名称统一转成小写。
+// Group 返回指定前缀的属性值集合并进行分组，属性名称统一转成小写，支持宽松匹配。
 func (p *priorityProperties) Group(key string) map[string]map[string]interface{} {
-	panic(util.UnimplementedMethod)
+	key = strings.ToLower(key)
+	result := make(map[string]map[string]interface{})
+	for k, v := range p.Prefix(key) {
+		if k == key {
+			continue
+		}
+		group := strings.SplitN(trimKeyPrefix(k, key), ".", 2)[0]
+		m, ok := result[group]
+		if !ok {
+			m = make(map[string]interface{})
+			result[group] = m
+		}
+		m[k] = v
+	}
+	return result
 }
 
 // InsertBefore 在 next 之前增加一层属性值列表
@@ -94,3 +94,40 @@ func TestPriorityProperties_InsertBefore(t *testing.T) {
 
 	util.AssertEqual(t, l0.Depth(), 5)
 }
+
+func TestPriorityProperties_Typed(t *testing.T) {
+
+	p1 := conf.New()
+	p1.Set("server.port", "8080")
+	p1.Set("server.timeout", "3s")
+	p1.Set("server.hosts", "a,b")
+
+	p2 := conf.New()
+	p2.Set("server.port", "80")
+	p2.Set("server.max-size", "10MB")
+	p2.Set("client.port", "9090")
+
+	p := conf.Priority(p1, p2)
+
+	util.AssertEqual(t, p.MustGetInt("server.port"), int64(8080))
+	util.AssertEqual(t, p.MustGetSize("server.maxSize"), int64(10<<20))
+	util.AssertEqual(t, p.Prefix("server"), map[string]interface{}{
+		"server.port":     "8080",
+		"server.timeout":  "3s",
+		"server.hosts":    "a,b",
+		"server.max-size": "10MB",
+	})
+	util.AssertEqual(t, len(p.Keys()), 5)
+	util.AssertEqual(t, len(p.Group("server")), 4)
+
+	var s struct {
+		Port    int      `value:"${port}"`
+		Hosts   []string `value:"${hosts}"`
+		MaxSize string   `value:"${max-size}"`
+	}
+	err := p.Bind("server", &s)
+	util.AssertEqual(t, err, nil)
+	util.AssertEqual(t, s.Port, 8080)
+	util.AssertEqual(t, s.Hosts, []string{"a", "b"})
+	util.AssertEqual(t, s.MaxSize, "10MB")
+}
@@ -20,15 +20,17 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
-	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-spring/spring-core/log"
 	"github.com/go-spring/spring-core/util"
 	"github.com/spf13/cast"
-	"github.com/spf13/viper"
 )
 
 // errorType error 的反射类型
@@ -37,13 +39,16 @@ var errorType = reflect.TypeOf((*error)(nil)).Elem()
 // Converter 类型转换器，函数原型 func(string)(type,error)
 type Converter interface{}
 
+// WatchFunc 属性值变化的监听函数，key 是发生变化的属性名，属性被删除时 newValue 为 nil。
+type WatchFunc func(key string, oldValue, newValue interface{})
+
 // Properties 定义属性值接口
 type Properties interface {
 
-	// Load 加载属性配置，支持 properties、yaml 和 toml 三种文件格式。
+	// Load 加载属性配置，按照扩展名选择配置类型，支持 properties、yaml、toml、json、ini、env 等格式。
 	Load(filename string) error
 
-	// Read 读取属性配置，支持 properties、yaml 和 toml 三种文件格式。
+	// Read 读取属性配置，configType 为空时根据内容推测配置类型。
 	Read(reader io.Reader, configType string) error
 
 	// Convert 添加类型转换器
@@ -52,6 +57,12 @@ type Properties interface {
 	// Converters 返回类型转换器集合
 	Converters() map[reflect.Type]Converter
 
+	// SetEnv 设置解析引用时查找环境变量的函数，默认使用 os.LookupEnv，为 nil 时不查找。
+	SetEnv(fn EnvLookup)
+
+	// Env 返回解析引用时查找环境变量的函数
+	Env() EnvLookup
+
 	// Has 查询属性值是否存在，属性名称统一转成小写。
 	Has(key string) bool
 
@@ -67,9 +78,72 @@ type Properties interface {
 	// GetDefault 返回属性值，如果没有找到则使用指定的默认值，属性名称统一转成小写。
 	GetDefault(key string, def interface{}) interface{}
 
-	// Set 设置属性值，属性名称统一转成小写。
+	// GetBool 返回布尔类型的属性值，属性不存在或者无法转换时返回错误。
+	GetBool(key string) (bool, error)
+
+	// MustGetBool 返回布尔类型的属性值，属性不存在或者无法转换时 panic。
+	MustGetBool(key string) bool
+
+	// GetInt 返回整数类型的属性值，属性不存在或者无法转换时返回错误。
+	GetInt(key string) (int64, error)
+
+	// MustGetInt 返回整数类型的属性值，属性不存在或者无法转换时 panic。
+	MustGetInt(key string) int64
+
+	// GetUint 返回无符号整数类型的属性值，属性不存在或者无法转换时返回错误。
+	GetUint(key string) (uint64, error)
+
+	// MustGetUint 返回无符号整数类型的属性值，属性不存在或者无法转换时 panic。
+	MustGetUint(key string) uint64
+
+	// GetFloat 返回浮点类型的属性值，属性不存在或者无法转换时返回错误。
+	GetFloat(key string) (float64, error)
+
+	// MustGetFloat 返回浮点类型的属性值，属性不存在或者无法转换时 panic。
+	MustGetFloat(key string) float64
+
+	// GetString 返回字符串类型的属性值，属性不存在或者无法转换时返回错误。
+	GetString(key string) (string, error)
+
+	// MustGetString 返回字符串类型的属性值，属性不存在或者无法转换时 panic。
+	MustGetString(key string) string
+
+	// GetDuration 返回时长类型的属性值，属性不存在或者无法转换时返回错误。
+	GetDuration(key string) (time.Duration, error)
+
+	// MustGetDuration 返回时长类型的属性值，属性不存在或者无法转换时 panic。
+	MustGetDuration(key string) time.Duration
+
+	// GetTime 返回时间类型的属性值，属性不存在或者无法转换时返回错误。
+	GetTime(key string) (time.Time, error)
+
+	// MustGetTime 返回时间类型的属性值，属性不存在或者无法转换时 panic。
+	MustGetTime(key string) time.Time
+
+	// GetStringSlice 返回字符串切片类型的属性值，字符串按照逗号进行切割，属性不存在或者无法转换时返回错误。
+	GetStringSlice(key string) ([]string, error)
+
+	// MustGetStringSlice 返回字符串切片类型的属性值，属性不存在或者无法转换时 panic。
+	MustGetStringSlice(key string) []string
+
+	// GetSize 返回字节数的属性值，支持 10MB 这样带单位的形式，属性不存在或者无法转换时返回错误。
+	GetSize(key string) (int64, error)
+
+	// MustGetSize 返回字节数的属性值，属性不存在或者无法转换时 panic。
+	MustGetSize(key string) int64
+
+	// Set 设置属性值，属性名称统一转成小写，value 为 nil 时删除该属性。
 	Set(key string, value interface{})
 
+	// Watch 监听属性值的变化，key 本身或者以 key 为前缀的属性发生变化时都会通知 fn。
+	Watch(key string, fn WatchFunc)
+
+	// Origin 返回属性值的来源，没有记录来源时返回 nil，属性名称统一转成小写。
+	Origin(key string) *Origin
+
+	// SetOrigin 记录属性值的来源，属性名称统一转成小写。
+	SetOrigin(key string, origin *Origin)
+
 	// Keys 返回所有键，属性名称统一转成小写。
 	Keys() []string
 
@@ -88,8 +162,13 @@ type Properties interface {
 
 // defaultProperties Properties 的默认实现
 type defaultProperties struct {
+	mutex      sync.RWMutex
 	properties map[string]interface{}
 	converters map[reflect.Type]Converter
+	watchers   map[string][]WatchFunc
+	origins    map[string]*Origin // 属性值的来源
+	relaxed    map[string]string  // 属性名的规范形式到实际名称的索引
+	env        EnvLookup          // 属性不存在时查找环境变量
 }
 
 // New defaultProperties 的构造函数
@@ -98,6 +177,10 @@ func New() *defaultProperties {
 	p := &defaultProperties{
 		properties: make(map[string]interface{}),
 		converters: make(map[reflect.Type]Converter),
+		watchers:   make(map[string][]WatchFunc),
+		origins:    make(map[string]*Origin),
+		relaxed:    make(map[string]string),
+		env:        os.LookupEnv,
 	}
 
 	// 注册时长转换函数 string -> time.Duration converter
@@ -108,45 +191,35 @@ func New() *defaultProperties {
 	// 支持非常多的日期格式，参见 cast.StringToDate。
 	_ = p.Convert(func(s string) (time.Time, error) { return cast.ToTimeE(s) })
 
+	// 注册字节数、IP 地址、URL 和正则表达式的转换函数
+	_ = p.Convert(parseByteSize)
+	_ = p.Convert(parseIP)
+	_ = p.Convert(parseURL)
+	_ = p.Convert(parseRegexp)
+
 	return p
 }
 
-// Load 加载属性配置，支持 properties、yaml 和 toml 三种文件格式。
+// Load 加载属性配置，按照扩展名选择配置类型，支持 properties、yaml、toml、json、ini、env 等格式。
 func (p *defaultProperties) Load(filename string) error {
 	log.Debug("load properties from file: ", filename)
 
-	return p.read(func(v *viper.Viper) error {
-		v.SetConfigFile(filename)
-		return v.ReadInConfig()
-	})
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return p.readProperties(b, filepath.Ext(filename))
 }
 
-// Read 读取属性配置，支持 properties、yaml 和 toml 三种文件格式。
+// Read 读取属性配置，configType 为空时根据内容推测配置类型，参见 RegisterReader。
 func (p *defaultProperties) Read(reader io.Reader, configType string) error {
 	log.Debug("load properties from reader type: ", configType)
 
-	return p.read(func(v *viper.Viper) error {
-		v.SetConfigType(configType)
-		return v.ReadConfig(reader)
-	})
-}
-
-func (p *defaultProperties) read(reader func(*viper.Viper) error) error {
-
-	v := viper.New()
-	if err := reader(v); err != nil {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
 		return err
 	}
-
-	keys := v.AllKeys()
-	sort.Strings(keys)
-
-	for _, key := range keys {
-		val := v.Get(key)
-		p.Set(key, val)
-		log.Tracef("%s=%v", key, val)
-	}
-	return nil
+	return p.readProperties(b, configType)
 }
 
 // validConverter 返回是否是合法的类型转换器。
@@ -160,7 +233,9 @@ func validConverter(t reflect.Type) bool {
 		return false
 	}
 
-	return util.IsValueType(t.Out(0).Kind()) && t.Out(1) == errorType
+	// 指针和切片类型也可以作为转换的目标，例如 *url.URL 和 net.IP
+	k := t.Out(0).Kind()
+	return (util.IsValueType(k) || k == reflect.Ptr || k == reflect.Slice) && t.Out(1) == errorType
 }
 
 // Convert 添加类型转换器
@@ -177,47 +252,279 @@ func (p *defaultProperties) Converters() map[reflect.Type]Converter {
 	return p.converters
 }
 
-// Has 查询属性值是否存在，属性名称统一转成小写。
+// SetEnv 设置解析引用时查找环境变量的函数，默认使用 os.LookupEnv，为 nil 时不查找。
+func (p *defaultProperties) SetEnv(fn EnvLookup) {
+	p.env = fn
+}
+
+// Env 返回解析引用时查找环境变量的函数
+func (p *defaultProperties) Env() EnvLookup {
+	return p.env
+}
+
+// find 返回属性实际使用的名称，首先精确匹配，然后按照规范形式进行宽松匹配，
+// 参见 CanonicalKey，调用者需要持有锁。
+func (p *defaultProperties) find(key string) (string, bool) {
+	k := strings.ToLower(key)
+	if _, ok := p.properties[k]; ok {
+		return k, true
+	}
+	if k, ok := p.relaxed[CanonicalKey(key)]; ok {
+		return k, true
+	}
+	return "", false
+}
+
+// Has 查询属性值是否存在，属性名称统一转成小写，支持宽松匹配。
 func (p *defaultProperties) Has(key string) bool {
-	_, ok := p.properties[strings.ToLower(key)]
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	_, ok := p.find(key)
 	return ok
 }
 
-// Get 返回属性值，不能存在返回 nil，属性名称统一转成小写。
+// Get 返回属性值，不能存在返回 nil，属性名称统一转成小写，支持宽松匹配。
 func (p *defaultProperties) Get(key string) interface{} {
-	if v, ok := p.properties[strings.ToLower(key)]; ok {
-		return v
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	if k, ok := p.find(key); ok {
+		return p.properties[k]
 	}
 	return nil
 }
 
-// GetFirst 返回 keys 中第一个存在的属性值，属性名称统一转成小写。
+// GetFirst 返回 keys 中第一个存在的属性值，属性名称统一转成小写，支持宽松匹配。
 func (p *defaultProperties) GetFirst(keys ...string) interface{} {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
 	for _, key := range keys {
-		if v, ok := p.properties[strings.ToLower(key)]; ok {
-			return v
+		if k, ok := p.find(key); ok {
+			return p.properties[k]
 		}
 	}
 	return nil
 }
 
-// GetDefault 返回属性值，如果没有找到则使用指定的默认值，属性名称统一转成小写。
+// GetDefault 返回属性值，如果没有找到则使用指定的默认值，属性名称统一转成小写，支持宽松匹配。
 func (p *defaultProperties) GetDefault(key string, def interface{}) interface{} {
-	if v, ok := p.properties[strings.ToLower(key)]; ok {
-		return v
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	if k, ok := p.find(key); ok {
+		return p.properties[k]
 	}
 	return def
 }
 
-// Set 设置属性值，属性名称统一转成小写。
+// GetBool 返回布尔类型的属性值，属性不存在或者无法转换时返回错误。
+func (p *defaultProperties) GetBool(key string) (bool, error) {
+	return getBool(p, key)
+}
+
+// MustGetBool 返回布尔类型的属性值，属性不存在或者无法转换时 panic。
+func (p *defaultProperties) MustGetBool(key string) bool {
+	v, err := getBool(p, key)
+	mustValue(err)
+	return v
+}
+
+// GetInt 返回整数类型的属性值，属性不存在或者无法转换时返回错误。
+func (p *defaultProperties) GetInt(key string) (int64, error) {
+	return getInt(p, key)
+}
+
+// MustGetInt 返回整数类型的属性值，属性不存在或者无法转换时 panic。
+func (p *defaultProperties) MustGetInt(key string) int64 {
+	v, err := getInt(p, key)
+	mustValue(err)
+	return v
+}
+
+// GetUint 返回无符号整数类型的属性值，属性不存在或者无法转换时返回错误。
+func (p *defaultProperties) GetUint(key string) (uint64, error) {
+	return getUint(p, key)
+}
+
+// MustGetUint 返回无符号整数类型的属性值，属性不存在或者无法转换时 panic。
+func (p *defaultProperties) MustGetUint(key string) uint64 {
+	v, err := getUint(p, key)
+	mustValue(err)
+	return v
+}
+
+// GetFloat 返回浮点类型的属性值，属性不存在或者无法转换时返回错误。
+func (p *defaultProperties) GetFloat(key string) (float64, error) {
+	return getFloat(p, key)
+}
+
+// MustGetFloat 返回浮点类型的属性值，属性不存在或者无法转换时 panic。
+func (p *defaultProperties) MustGetFloat(key string) float64 {
+	v, err := getFloat(p, key)
+	mustValue(err)
+	return v
+}
+
+// GetString 返回字符串类型的属性值，属性不存在或者无法转换时返回错误。
+func (p *defaultProperties) GetString(key string) (string, error) {
+	return getString(p, key)
+}
+
+// MustGetString 返回字符串类型的属性值，属性不存在或者无法转换时 panic。
+func (p *defaultProperties) MustGetString(key string) string {
+	v, err := getString(p, key)
+	mustValue(err)
+	return v
+}
+
+// GetDuration 返回时长类型的属性值，属性不存在或者无法转换时返回错误。
+func (p *defaultProperties) GetDuration(key string) (time.Duration, error) {
+	return getDuration(p, key)
+}
+
+// MustGetDuration 返回时长类型的属性值，属性不存在或者无法转换时 panic。
+func (p *defaultProperties) MustGetDuration(key string) time.Duration {
+	v, err := getDuration(p, key)
+	mustValue(err)
+	return v
+}
+
+// GetTime 返回时间类型的属性值，属性不存在或者无法转换时返回错误。
+func (p *defaultProperties) GetTime(key string) (time.Time, error) {
+	return getTime(p, key)
+}
+
+// MustGetTime 返回时间类型的属性值，属性不存在或者无法转换时 panic。
+func (p *defaultProperties) MustGetTime(key string) time.Time {
+	v, err := getTime(p, key)
+	mustValue(err)
+	return v
+}
+
+// GetStringSlice 返回字符串切片类型的属性值，字符串按照逗号进行切割，属性不存在或者无法转换时返回错误。
+func (p *defaultProperties) GetStringSlice(key string) ([]string, error) {
+	return getStringSlice(p, key)
+}
+
+// MustGetStringSlice 返回字符串切片类型的属性值，属性不存在或者无法转换时 panic。
+func (p *defaultProperties) MustGetStringSlice(key string) []string {
+	v, err := getStringSlice(p, key)
+	mustValue(err)
+	return v
+}
+
+// GetSize 返回字节数的属性值，支持 10MB 这样带单位的形式，属性不存在或者无法转换时返回错误。
+func (p *defaultProperties) GetSize(key string) (int64, error) {
+	return getSize(p, key)
+}
+
+// MustGetSize 返回字节数的属性值，属性不存在或者无法转换时 panic。
+func (p *defaultProperties) MustGetSize(key string) int64 {
+	v, err := getSize(p, key)
+	mustValue(err)
+	return v
+}
+
+// Set 设置属性值，属性名称统一转成小写，value 为 nil 时删除该属性。已经存在
+// 宽松匹配的属性时更新该属性，而不是添加一个新的属性，这时会输出一条警告。
 func (p *defaultProperties) Set(key string, value interface{}) {
-	p.properties[strings.ToLower(key)] = value
+
+	p.mutex.Lock()
+	name := strings.ToLower(key)
+	if k, ok := p.find(key); ok {
+		key = k
+	} else {
+		key = name
+	}
+	oldValue, ok := p.properties[key]
+	if ok && value != nil && key != name && !reflect.DeepEqual(oldValue, value) {
+		log.Warnf("property %s overrides %s, they have the same relaxed name", name, key)
+	}
+	if value == nil {
+		delete(p.properties, key)
+		delete(p.origins, key)
+		if ck := CanonicalKey(key); p.relaxed[ck] == key {
+			delete(p.relaxed, ck)
+		}
+	} else {
+		p.properties[key] = value
+		if p.relaxed == nil {
+			p.relaxed = make(map[string]string)
+		}
+		p.relaxed[CanonicalKey(key)] = key
+	}
+	watchers := p.matchWatchers(key)
+	p.mutex.Unlock()
+
+	// 值没有发生变化时无需通知，在锁外通知以便监听函数可以读取属性值
+	if (ok || value != nil) && !reflect.DeepEqual(oldValue, value) {
+		for _, fn := range watchers {
+			fn(key, oldValue, value)
+		}
+	}
+}
+
+// matchWatchers 返回监听 key 本身或者 key 的前缀的监听函数，调用者需要持有锁。
+func (p *defaultProperties) matchWatchers(key string) []WatchFunc {
+	var result []WatchFunc
+	for k, watchers := range p.watchers {
+		if hasKeyPrefix(key, k) {
+			result = append(result, watchers...)
+		}
+	}
+	return result
+}
+
+// Watch 监听属性值的变化，key 本身或者以 key 为前缀的属性发生变化时都会通知 fn。
+func (p *defaultProperties) Watch(key string, fn WatchFunc) {
+	key = strings.ToLower(key)
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.watchers[key] = append(p.watchers[key], fn)
+}
+
+// Origin 返回属性值的来源，没有记录来源时返回 nil，属性名称统一转成小写，支持宽松匹配。
+func (p *defaultProperties) Origin(key string) *Origin {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	if k, ok := p.find(key); ok {
+		return p.origins[k]
+	}
+	return p.origins[strings.ToLower(key)] // 来源可以在设置属性值之前记录
+}
+
+// SetOrigin 记录属性值的来源，属性名称统一转成小写，支持宽松匹配。
+func (p *defaultProperties) SetOrigin(key string, origin *Origin) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if k, ok := p.find(key); ok {
+		key = k
+	} else {
+		key = strings.ToLower(key)
+	}
+	if p.origins == nil {
+		p.origins = make(map[string]*Origin)
+	}
+	if origin == nil {
+		delete(p.origins, key)
+	} else {
+		p.origins[key] = origin
+	}
+}
+
+// snapshot 返回属性值的浅拷贝，以便在锁外进行遍历。
+func (p *defaultProperties) snapshot() map[string]interface{} {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	result := make(map[string]interface{}, len(p.properties))
+	for k, v := range p.properties {
+		result[k] = v
+	}
+	return result
 }
 
 // Keys 返回所有键，属性名称统一转成小写。
 func (p *defaultProperties) Keys() []string {
 	var keys []string
-	for k := range p.properties {
+	for k := range p.snapshot() {
 		keys = append(keys, k)
 	}
 	return keys
@@ -225,37 +532,37 @@ func (p *defaultProperties) Keys() []string {
 
 // Range 遍历所有的属性值，属性名称统一转成小写。
 func (p *defaultProperties) Range(fn func(string, interface{})) {
-	for key, val := range p.properties {
+	for key, val := range p.snapshot() {
 		fn(key, val)
 	}
 }
 
 // Fill 返回所有的属性值，属性名称统一转成小写。
 func (p *defaultProperties) Fill(properties map[string]interface{}) {
-	for key, val := range p.properties {
+	for key, val := range p.snapshot() {
 		properties[key] = val
 	}
 }
 
-// Prefix 返回指定前缀的属性值集合，属性名称统一转成小写。
+// Prefix 返回指定前缀的属性值集合，属性名称统一转成小写，支持宽松匹配。
 func (p *defaultProperties) Prefix(key string) map[string]interface{} {
 	key = strings.ToLower(key)
 	result := make(map[string]interface{})
-	for k, v := range p.properties {
-		if k == key || strings.HasPrefix(k, key+".") {
+	for k, v := range p.snapshot() {
+		if hasKeyPrefix(k, key) {
 			result[k] = v
 		}
 	}
 	return result
 }
 
-// Group 返回指定前缀的属性值集合并进行分组，属性名称统一转成小写。
+// Group 返回指定前缀的属性值集合并进行分组，属性名称统一转成小写，支持宽松匹配。
 func (p *defaultProperties) Group(key string) map[string]map[string]interface{} {
-	key = strings.ToLower(key) + "."
+	key = strings.ToLower(key)
 	result := make(map[string]map[string]interface{})
-	for k, v := range p.properties {
-		if strings.HasPrefix(k, key) {
-			ss := strings.SplitN(k[len(key):], ".", 2)
+	for k, v := range p.snapshot() {
+		if k != key && hasKeyPrefix(k, key) {
+			ss := strings.SplitN(trimKeyPrefix(k, key), ".", 2)
 			group := ss[0]
 			m, ok := result[group]
 			if !ok {
@@ -270,13 +577,18 @@ func (p *defaultProperties) Group(key string) map[string]map[string]interface{}
 
 // BindOption 属性值绑定可选项
 type BindOption struct {
-	PrefixName string // 属性名前缀
-	FullName   string // 完整属性名
-	FieldName  string // 结构体字段的名称
+	PrefixName string    // 属性名前缀
+	FullName   string    // 完整属性名
+	FieldName  string    // 结构体字段的名称
+	Validate   string    // 字段的 validate 标签，绑定成功后进行校验，参见 validateValue
+	Metadata   *Metadata // 记录绑定的属性的元数据，为 nil 时不记录
+	Owner      string    // 使用属性的 Bean，记录元数据时使用
+	Layout     string    // 字段的 layout 标签，time.Time 按照该格式解析，例如 2006-01-02
 }
 
-// BindStruct 对结构体进行属性值绑定
+// BindStruct 对结构体进行属性值绑定，遇到错误时继续绑定其他字段，最后返回所有的错误。
 func BindStruct(p Properties, v reflect.Value, opt BindOption) error {
+	var errs BindErrors
 	t := v.Type()
 	for i := 0; i < t.NumField(); i++ {
 		ft := t.Field(i)
@@ -291,11 +603,15 @@ func BindStruct(p Properties, v reflect.Value, opt BindOption) error {
 			PrefixName: opt.PrefixName,
 			FullName:   opt.FullName,
 			FieldName:  subFieldName,
+			Validate:   ft.Tag.Get("validate"),
+			Metadata:   opt.Metadata,
+			Owner:      opt.Owner,
+			Layout:     ft.Tag.Get("layout"),
 		}
 
 		if tag, ok := ft.Tag.Lookup("value"); ok {
 			if err := BindStructField(p, fv, tag, subOpt); err != nil {
-				return err
+				errs = appendError(errs, err)
 			}
 			continue
 		}
@@ -303,20 +619,19 @@ func BindStruct(p Properties, v reflect.Value, opt BindOption) error {
 		// 匿名嵌套需要处理，不是结构体的具名字段无需处理
 		if ft.Anonymous || ft.Type.Kind() == reflect.Struct {
 			if err := BindStruct(p, fv, subOpt); err != nil {
-				return err
+				errs = appendError(errs, err)
 			}
 		}
 	}
-	return nil
+	return errs.toError()
 }
 
 // parsePropertyTag 解析属性值标签
 func parsePropertyTag(str string) (key string, def interface{}) {
-	ss := strings.SplitN(str, ":=", 2)
-	if len(ss) > 1 {
-		def = ss[1]
+	key, s, ok := splitPlaceholder(str)
+	if ok {
+		def = s
 	}
-	key = ss[0]
 	return
 }
 
@@ -328,13 +643,21 @@ func BindStructField(p Properties, v reflect.Value, str string, opt BindOption)
 		return fmt.Errorf("%s 属性绑定的语法发生错误", opt.FieldName)
 	}
 
-	// 指针不能作为属性绑定的目标
-	if v.Kind() == reflect.Ptr {
-		return fmt.Errorf("%s 属性绑定的目标不能是指针", opt.FieldName)
+	// 多级指针不能作为属性绑定的目标
+	if v.Kind() == reflect.Ptr && v.Type().Elem().Kind() == reflect.Ptr {
+		return fmt.Errorf("%s 属性绑定的目标不能是多级指针", opt.FieldName)
 	}
 
 	key, def := parsePropertyTag(str[2 : len(str)-1])
 
+	// 属性名中可能嵌套引用，例如 ${a.${env}.url}
+	if strings.Contains(key, "${") {
+		var err error
+		if key, err = ResolveString(p, key); err != nil {
+			return err
+		}
+	}
+
 	// 此处使用最短属性名
 	if opt.FullName == "" {
 		opt.FullName = key
@@ -347,41 +670,101 @@ func BindStructField(p Properties, v reflect.Value, str string, opt BindOption)
 		key = opt.PrefixName + "." + key
 	}
 
-	return BindValue(p, v, key, def, opt)
-}
-
-// ResolveProperty 解析属性值，查看其是否具有引用关系
-func ResolveProperty(p Properties, _ string, value interface{}) (interface{}, error) {
-	str, ok := value.(string)
+	// 结构体的每个字段单独记录，除非结构体作为属性值绑定
+	if opt.Metadata != nil && key != "" {
+		t := v.Type()
+		if t.Kind() == reflect.Ptr && !hasConverter(p, t, opt) {
+			t = t.Elem()
+		}
+		if hasConverter(p, t, opt) || t.Kind() != reflect.Struct {
+			meta := PropertyMeta{Key: key, Type: v.Type().String()}
+			if def != nil {
+				meta.Default = cast.ToString(def)
+			}
+			if opt.Owner != "" {
+				meta.Owners = []string{opt.Owner}
+			}
+			opt.Metadata.Register(meta)
+		}
+	}
 
-	// 不是字符串或者没有使用配置引用语法
-	if !ok || !strings.HasPrefix(str, "${") {
-		return value, nil
+	if err := BindValue(p, v, key, def, opt); err != nil {
+		switch err.(type) {
+		case *BindError, BindErrors: // 结构体字段已经记录了属性名
+			return err
+		}
+		if origin := p.Origin(key); origin != nil {
+			return &BindError{Key: key, Origin: origin, Err: err}
+		}
+		return err
 	}
 
-	key, def := parsePropertyTag(str[2 : len(str)-1])
-	if val := p.GetDefault(key, def); val != nil {
-		return ResolveProperty(p, key, val)
+	if opt.Validate != "" {
+		if err := validateValue(v, opt.Validate); err != nil {
+			return &BindError{Key: key, Origin: p.Origin(key), Err: err}
+		}
 	}
+	return nil
+}
 
-	return nil, fmt.Errorf("property \"%s\" not config", key)
+// validateValue 使用设置的参数校验器校验绑定后的值，没有设置时使用 util.DefaultValidator，
+// 值被包装成只有一个字段的结构体，以便校验器能够读取该字段的 validate 标签。
+func validateValue(v reflect.Value, tag string) error {
+	t := reflect.StructOf([]reflect.StructField{{
+		Name: "Value",
+		Type: v.Type(),
+		Tag:  reflect.StructTag(fmt.Sprintf("validate:%q", tag)),
+	}})
+	s := reflect.New(t)
+	s.Elem().Field(0).Set(util.PatchValue(v, true))
+	validator := util.GetValidator()
+	if validator == nil {
+		validator = util.DefaultValidator
+	}
+	err := validator.Validate(s.Interface())
+	if errs, ok := err.(util.FieldErrors); ok && len(errs) == 1 {
+		return errs[0].Err
+	}
+	return err
 }
 
 func getPropertyValue(p Properties, kind reflect.Kind, key string, def interface{}, opt BindOption) (interface{}, error) {
 
-	// 首先获取精确匹配的属性值
+	// 首先获取精确匹配的属性值，属性值和默认值一样需要解析配置引用语法
 	if val := p.Get(key); val != nil {
-		return val, nil
+		return resolveStored(p, key, val)
+	}
+
+	// Slice 类型获取 key[0]、key[1].name 等索引形式的属性值
+	if kind == reflect.Slice {
+		indexedValue, err := getIndexedValue(p, key)
+		if err != nil {
+			return nil, err
+		}
+		if len(indexedValue) > 0 {
+			return indexedValue, nil
+		}
 	}
 
 	// Map 和 Struct 类型获取具有相同前缀的属性值
 	if kind == reflect.Map || kind == reflect.Struct {
 		if prefixValue := p.Prefix(key); len(prefixValue) > 0 {
+			for k, v := range prefixValue {
+				rv, err := resolveStored(p, k, v)
+				if err != nil {
+					return nil, err
+				}
+				prefixValue[k] = rv
+			}
 			return prefixValue, nil
 		}
 	}
 
-	// 最后使用默认值，需要解析配置引用语法
+	// 然后和引用语法一样查找环境变量，最后使用默认值
+	r := &resolver{p: p}
+	if val, ok := r.lookupEnv(key); ok {
+		return ResolveProperty(p, key, val)
+	}
 	if def != nil {
 		return ResolveProperty(p, key, def)
 	}
@@ -396,14 +779,27 @@ func BindValue(p Properties, v reflect.Value, key string, def interface{}, opt B
 	k := t.Kind()
 
 	// 存在值类型转换器的情况下结构体优先使用属性值绑定
-	if fn, ok := p.Converters()[t]; ok {
+	if hasConverter(p, t, opt) {
 		propValue, err := getPropertyValue(p, k, key, def, opt)
-		if err == nil {
-			fnValue := reflect.ValueOf(fn)
-			out := fnValue.Call([]reflect.Value{reflect.ValueOf(propValue)})
-			v.Set(out[0])
+		if err != nil {
+			return err
 		}
-		return err
+		cv, err := convertValue(p, t, propValue, opt)
+		if err != nil {
+			return err
+		}
+		v.Set(cv)
+		return nil
+	}
+
+	// 指针类型绑定到新创建的指向的值上
+	if k == reflect.Ptr {
+		ev := reflect.New(t.Elem())
+		if err := BindValue(p, ev.Elem(), key, def, opt); err != nil {
+			return err
+		}
+		v.Set(ev)
+		return nil
 	}
 
 	if k == reflect.Struct {
@@ -412,6 +808,8 @@ func BindValue(p Properties, v reflect.Value, key string, def interface{}, opt B
 				PrefixName: key,
 				FullName:   opt.FullName,
 				FieldName:  opt.FieldName,
+				Metadata:   opt.Metadata,
+				Owner:      opt.Owner,
 			})
 		} else { // 前面已经校验过是否存在值类型转换器
 			return fmt.Errorf("%s 结构体字段不能指定默认值", opt.FieldName)
@@ -464,13 +862,17 @@ func BindValue(p Properties, v reflect.Value, key string, def interface{}, opt B
 		}
 
 		// 处理使用类型转换器的场景
-		if fn, ok := p.Converters()[elemType]; ok {
+		if hasConverter(p, elemType, opt) {
 			if s0, err := cast.ToStringSliceE(propValue); err == nil {
 				sv := reflect.MakeSlice(t, len(s0), len(s0))
-				fnValue := reflect.ValueOf(fn)
 				for i, iv := range s0 {
-					res := fnValue.Call([]reflect.Value{reflect.ValueOf(iv)})
-					sv.Index(i).Set(res[0])
+					elemOpt := opt
+					elemOpt.FullName = fmt.Sprintf("%s[%d]", opt.FullName, i)
+					ev, err := convertValue(p, elemType, iv, elemOpt)
+					if err != nil {
+						return err
+					}
+					sv.Index(i).Set(ev)
 				}
 				v.Set(sv)
 				return nil
@@ -562,7 +964,7 @@ func BindValue(p Properties, v reflect.Value, key string, def interface{}, opt B
 					if sv, err := cast.ToStringMapE(si); err == nil {
 						ev := reflect.New(elemType)
 						subFullName := fmt.Sprintf("%s[%d]", key, i)
-						err = BindStruct(&defaultProperties{sv, p.Converters()}, ev.Elem(), BindOption{
+						err = BindStruct(subProperties(p, sv), ev.Elem(), BindOption{
 							FullName:  subFullName,
 							FieldName: opt.FieldName,
 						})
@@ -588,15 +990,18 @@ func BindValue(p Properties, v reflect.Value, key string, def interface{}, opt B
 		elemKind := elemType.Kind()
 
 		// 首先处理使用类型转换器的场景
-		if fn, ok := p.Converters()[elemType]; ok {
+		if hasConverter(p, elemType, opt) {
 			if mapValue, err := cast.ToStringMapStringE(propValue); err == nil {
-				prefix := key + "."
-				fnValue := reflect.ValueOf(fn)
 				result := reflect.MakeMap(t)
 				for k0, v0 := range mapValue {
-					res := fnValue.Call([]reflect.Value{reflect.ValueOf(v0)})
-					k0 = strings.TrimPrefix(k0, prefix)
-					result.SetMapIndex(reflect.ValueOf(k0), res[0])
+					k0 = trimKeyPrefix(k0, key)
+					elemOpt := opt
+					elemOpt.FullName = opt.FullName + "." + k0
+					ev, err := convertValue(p, elemType, v0, elemOpt)
+					if err != nil {
+						return err
+					}
+					result.SetMapIndex(reflect.ValueOf(k0), ev)
 				}
 				v.Set(result)
 				return nil
@@ -616,10 +1021,9 @@ func BindValue(p Properties, v reflect.Value, key string, def interface{}, opt B
 			return errors.New("暂未支持")
 		case reflect.String:
 			if mapValue, err := cast.ToStringMapStringE(propValue); err == nil {
-				prefix := key + "."
 				result := make(map[string]string)
 				for k0, v0 := range mapValue {
-					k0 = strings.TrimPrefix(k0, prefix)
+					k0 = trimKeyPrefix(k0, key)
 					result[k0] = v0
 				}
 				v.Set(reflect.ValueOf(result))
@@ -630,26 +1034,33 @@ func BindValue(p Properties, v reflect.Value, key string, def interface{}, opt B
 			// 处理结构体字段的场景
 			if mapValue, err := cast.ToStringMapE(propValue); err == nil {
 				temp := make(map[string]map[string]interface{})
-				trimKey := key + "."
 				var ok bool
 
-				// 将一维 map 变成二维 map
+				// 将一维 map 变成二维 map，元素本身是 map 时直接合并
 				for k0, v0 := range mapValue {
-					k0 = strings.TrimPrefix(k0, trimKey)
-					sk := strings.Split(k0, ".")
+					k0 = trimKeyPrefix(k0, key)
+					sk := strings.SplitN(k0, ".", 2)
 					var item map[string]interface{}
 					if item, ok = temp[sk[0]]; !ok {
 						item = make(map[string]interface{})
 						temp[sk[0]] = item
 					}
-					item[sk[1]] = v0
+					if len(sk) > 1 {
+						item[sk[1]] = v0
+					} else if m, err := cast.ToStringMapE(v0); err == nil {
+						for k2, v2 := range m {
+							item[k2] = v2
+						}
+					} else {
+						return fmt.Errorf("property value %s.%s isn't map[string]interface{}", key, k0)
+					}
 				}
 
 				result := reflect.MakeMapWithSize(t, len(temp))
 				for k1, v1 := range temp {
 					ev := reflect.New(elemType)
 					subFullName := fmt.Sprintf("%s.%s", key, k1)
-					err = BindStruct(&defaultProperties{v1, p.Converters()}, ev.Elem(), BindOption{
+					err = BindStruct(subProperties(p, v1), ev.Elem(), BindOption{
 						FullName:  subFullName,
 						FieldName: opt.FieldName,
 					})
@@ -17,10 +17,16 @@
 package conf_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"image"
+	"net"
+	"net/url"
+	"os"
 	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
@@ -798,3 +804,790 @@ func TestDefaultProperties_KeyCanBeEmpty(t *testing.T) {
 	p.Bind("", &s)
 	util.AssertEqual(t, s.KeyIsEmpty, "kie")
 }
+
+func TestDefaultProperties_Watch(t *testing.T) {
+
+	p := conf.New()
+	p.Set("db.url", "mysql://a")
+
+	var changes []string
+	p.Watch("DB", func(key string, oldValue, newValue interface{}) {
+		changes = append(changes, fmt.Sprintf("%s:%v->%v", key, oldValue, newValue))
+	})
+
+	p.Set("db.url", "mysql://a") // 值没有变化
+	p.Set("db.url", "mysql://b")
+	p.Set("db.user", "root")
+	p.Set("dbx", "ignored") // 只是字符串前缀相同
+	p.Set("db.user", nil)
+	p.Set("db.none", nil) // 删除不存在的属性
+
+	util.AssertEqual(t, changes, []string{
+		"db.url:mysql://a->mysql://b",
+		"db.user:<nil>->root",
+		"db.user:root-><nil>",
+	})
+	util.AssertEqual(t, p.Has("db.user"), false)
+}
+
+func TestDiff(t *testing.T) {
+	diff := conf.Diff(map[string]interface{}{
+		"a": "1",
+		"b": "2",
+		"c": []interface{}{"x", "y"},
+	}, map[string]interface{}{
+		"a": "1",
+		"c": []interface{}{"x", "z"},
+		"d": 4,
+	})
+	util.AssertEqual(t, diff, map[string]interface{}{
+		"b": nil,
+		"c": []interface{}{"x", "z"},
+		"d": 4,
+	})
+}
+
+func TestResolveProperty(t *testing.T) {
+
+	p := conf.New()
+	p.Set("host", "localhost")
+	p.Set("env", "test")
+	p.Set("db.test.url", "mysql://${host}")
+	p.Set("ports", []int{8080, 8081})
+	p.Set("cycle.a", "${cycle.b}")
+	p.Set("cycle.b", "x-${cycle.c}")
+	p.Set("cycle.c", "${cycle.a:=default}")
+
+	os.Setenv("RESOLVE_ENVNAME", "env")
+	defer os.Unsetenv("RESOLVE_ENVNAME")
+
+	testcases := []struct {
+		value  string
+		expect interface{}
+		err    error
+	}{
+		{"http://${host}:${port:=8080}/api", "http://localhost:8080/api", nil},
+		{"${db.${env}.url}", "mysql://localhost", nil},
+		{"${DB.${ENV}.URL}", "mysql://localhost", nil},
+		{"${ports}", []int{8080, 8081}, nil},
+		{"${none:=${host}}", "localhost", nil},
+		{"${none:=}", "", nil},
+		{`\${host} is ${host}`, "${host} is localhost", nil},
+		{`${host}\${host}`, "localhost${host}", nil},
+		{"${resolve.env-name}", "env", nil},
+		{"${none}", nil, errors.New(`property "none" not config`)},
+		{"a-${host", nil, errors.New(`placeholder "${host" isn't closed`)},
+		{"${cycle.a}", nil, errors.New("found circular reference: cycle.a => cycle.b => cycle.c => cycle.a")},
+	}
+
+	for _, c := range testcases {
+		v, err := conf.ResolveProperty(p, "", c.value)
+		util.AssertEqual(t, err, c.err)
+		util.AssertEqual(t, v, c.expect)
+	}
+
+	t.Run("self reference", func(t *testing.T) {
+		_, err := conf.ResolveProperty(p, "host", "${HOST}")
+		util.AssertEqual(t, err, errors.New("found circular reference: host => host"))
+	})
+
+	t.Run("bind", func(t *testing.T) {
+		var s struct {
+			URL  string `value:"${db.${env}.url}"`
+			Addr string `value:"${addr:=${host}:${port:=80}}"`
+		}
+		err := p.Bind("", &s)
+		util.AssertEqual(t, err, nil)
+		util.AssertEqual(t, s.URL, "mysql://localhost")
+		util.AssertEqual(t, s.Addr, "localhost:80")
+	})
+
+	t.Run("env", func(t *testing.T) {
+		p := conf.New()
+		p.SetEnv(func(name string) (string, bool) {
+			return "custom", name == "RESOLVE_ENVNAME"
+		})
+		v, err := conf.ResolveProperty(p, "", "${resolve.env-name}")
+		util.AssertEqual(t, err, nil)
+		util.AssertEqual(t, v, "custom")
+
+		p.SetEnv(nil)
+		_, err = conf.ResolveProperty(p, "", "${resolve.env-name}")
+		util.AssertEqual(t, err, errors.New(`property "resolve.env-name" not config`))
+	})
+
+	t.Run("env before default", func(t *testing.T) {
+		p := conf.New()
+		p.SetEnv(func(name string) (string, bool) {
+			return "88", name == "SERVER_OTHER"
+		})
+		var s struct {
+			Other  int `value:"${server.other:=1}"`
+			Nested int `value:"${none:=${server.other}}"`
+		}
+		err := p.Bind("", &s)
+		util.AssertEqual(t, err, nil)
+		util.AssertEqual(t, s.Other, 88)
+		util.AssertEqual(t, s.Nested, 88)
+	})
+
+	t.Run("escape in properties file", func(t *testing.T) {
+		p := conf.New()
+		err := p.Read(strings.NewReader("host=localhost\ntip=\\\\${host} is ${host}\n"), "properties")
+		util.AssertEqual(t, err, nil)
+		var s struct {
+			Tip string `value:"${tip}"`
+		}
+		util.AssertEqual(t, p.Bind("", &s), nil)
+		util.AssertEqual(t, s.Tip, "${host} is localhost")
+	})
+
+	t.Run("resolved origin", func(t *testing.T) {
+		p := conf.New()
+		p.Set("lit", "${notref}")
+		p.SetOrigin("lit", &conf.Origin{Resolved: true})
+		var s struct {
+			Lit string `value:"${lit}"`
+			Ref string `value:"${ref:=${lit}}"`
+		}
+		util.AssertEqual(t, p.Bind("", &s), nil)
+		util.AssertEqual(t, s.Lit, "${notref}")
+		util.AssertEqual(t, s.Ref, "${notref}")
+	})
+
+	t.Run("bind stored value", func(t *testing.T) {
+		p := conf.New()
+		p.Set("host", "localhost")
+		p.Set("url", "mysql://${host}")
+		p.Set("escape", `\${host}`)
+		p.Set("hosts[0]", "${host}")
+		p.Set("hosts[1]", "${host}.backup")
+		p.Set("labels.host", "${host}")
+		var s struct {
+			URL    string            `value:"${url}"`
+			Escape string            `value:"${escape}"`
+			Hosts  []string          `value:"${hosts}"`
+			Labels map[string]string `value:"${labels}"`
+		}
+		err := p.Bind("", &s)
+		util.AssertEqual(t, err, nil)
+		util.AssertEqual(t, s.URL, "mysql://localhost")
+		util.AssertEqual(t, s.Escape, "${host}")
+		util.AssertEqual(t, s.Hosts, []string{"localhost", "localhost.backup"})
+		util.AssertEqual(t, s.Labels, map[string]string{"host": "localhost"})
+		util.AssertEqual(t, p.Get("url"), "mysql://${host}") // 存储的属性值保持不变
+	})
+
+	t.Run("sensitive", func(t *testing.T) {
+		p := conf.New()
+		p.Set("db.host", "localhost")
+		p.Set("db.password", "p@ss")
+		p.Set("db.secret", "${db.password}")
+		v, sensitive, err := conf.ResolvePropertySensitive(p, "db.url", "mysql://root:${db.secret}@${db.host}/db")
+		util.AssertEqual(t, err, nil)
+		util.AssertEqual(t, v, "mysql://root:p@ss@localhost/db")
+		util.AssertEqual(t, sensitive, true)
+		v, sensitive, err = conf.ResolvePropertySensitive(p, "db.url", "mysql://${db.host}/db")
+		util.AssertEqual(t, err, nil)
+		util.AssertEqual(t, v, "mysql://localhost/db")
+		util.AssertEqual(t, sensitive, false)
+	})
+}
+
+func TestBindStruct_Errors(t *testing.T) {
+
+	type Server struct {
+		Host  string `value:"${host}" validate:"required"`
+		Port  int    `value:"${port}" validate:"min=1,max=65535"`
+		Level string `value:"${level:=info}" validate:"oneof=debug info warn"`
+	}
+
+	var s struct {
+		Server  Server `value:"${server}"`
+		Timeout int    `value:"${timeout}"`
+	}
+
+	p := conf.New()
+	p.Set("server.host", "")
+	p.Set("server.port", 70000)
+	p.Set("server.level", "trace")
+	p.Set("timeout", "abc")
+	p.SetOrigin("server.port", &conf.Origin{Layer: "app-config", File: "application.properties", Line: 2})
+	p.SetOrigin("timeout", &conf.Origin{Layer: "sys-env", EnvVar: "TIMEOUT"})
+
+	err := p.Bind("", &s)
+	errs, ok := err.(conf.BindErrors)
+	util.AssertEqual(t, ok, true)
+	util.AssertEqual(t, len(errs), 4)
+	util.AssertEqual(t, errs[0].Error(), "value is required (key: server.host)")
+	util.AssertEqual(t, errs[1].Error(), "value 70000 is greater than max 65535 (key: server.port, origin: app-config application.properties:2)")
+	util.AssertEqual(t, errs[2].Error(), "value trace isn't one of [debug info warn] (key: server.level)")
+	util.AssertEqual(t, errs[3].Error(), "property value timeout isn't int type (key: timeout, origin: sys-env TIMEOUT)")
+
+	// 多个属性层时使用第一个存在该属性的属性层记录的来源
+	p2 := conf.New()
+	p2.Set("timeout", "3")
+	p2.SetOrigin("timeout", &conf.Origin{Layer: "cmd-args"})
+	util.AssertEqual(t, conf.Priority(p2, p).Origin("timeout").String(), "cmd-args")
+	util.AssertEqual(t, conf.Priority(p2, p).Origin("server.port").String(), "app-config application.properties:2")
+}
+
+func TestDump(t *testing.T) {
+
+	p := conf.New()
+	p.Set("db.url", "mysql://localhost")
+	p.Set("db.password", "123456")
+	p.Set("api.key", "abc")
+	p.Set("name", "go")
+	p.SetOrigin("db.url", &conf.Origin{Layer: "app-config", File: "config/application.yaml", Line: 3})
+	p.SetOrigin("api.key", &conf.Origin{Layer: "sys-env", EnvVar: "API_KEY", Sensitive: true})
+
+	var buf strings.Builder
+	err := conf.Dump(p, &buf)
+	util.AssertEqual(t, err, nil)
+	util.AssertEqual(t, buf.String(), `api.key=****** # sys-env API_KEY
+db.password=******
+db.url=mysql://localhost # app-config config/application.yaml:3
+name=go
+`)
+}
+
+func TestIsSensitive(t *testing.T) {
+	p := conf.New()
+	for key, sensitive := range map[string]bool{
+		"db.password":           true,
+		"DB_PASSWORD":           true,
+		"anthropic_api_key":     true,
+		"apikey":                true,
+		"aws.access-key":        true,
+		"tls.private_key":       true,
+		"oauth.client-secret":   true,
+		"auth.token.value":      true,
+		"users[0].credentials":  true,
+		"max_context_tokens":    false,
+		"tokenizer.name":        false,
+		"db.password-policy":    true,
+		"server.secretary-name": false,
+		"monkey":                false,
+	} {
+		util.AssertEqual(t, conf.IsSensitive(p, key), sensitive)
+	}
+}
+
+func TestDefaultProperties_Relaxed(t *testing.T) {
+
+	util.AssertEqual(t, conf.CanonicalKey("server.max-conn"), "server.maxconn")
+	util.AssertEqual(t, conf.CanonicalKey("server.maxConn"), "server.maxconn")
+	util.AssertEqual(t, conf.CanonicalKey("server.max_conn"), "server.maxconn")
+	util.AssertEqual(t, conf.CanonicalKey("SERVER_MAXCONN"), "server.maxconn")
+	for _, key := range []string{"server.max-conn", "server.maxConn", "server.max_conn", "a.b-c"} {
+		util.AssertEqual(t, conf.CanonicalKey(conf.EnvName(key)), conf.CanonicalKey(key))
+		util.AssertEqual(t, conf.CanonicalKey(conf.EnvKey(conf.EnvName(key))), conf.CanonicalKey(key))
+	}
+	util.AssertEqual(t, conf.EnvName("server.max-conn"), "SERVER_MAXCONN")
+	util.AssertEqual(t, conf.EnvKey("SPRING_PROFILE"), "spring.profile")
+	util.AssertEqual(t, conf.EnvKey("http_proxy"), "http_proxy")
+
+	p := conf.New()
+	p.Set("server.max-conn", 100)
+	p.Set("server.hosts.host-a", "a")
+	p.Set("server.hosts.host-b", "b")
+
+	for _, key := range []string{"server.max-conn", "server.maxConn", "server.max_conn", "SERVER_MAXCONN"} {
+		util.AssertEqual(t, p.Has(key), true)
+		util.AssertEqual(t, p.Get(key), 100)
+	}
+	util.AssertEqual(t, p.Has("server.max.conn"), false)
+	util.AssertEqual(t, len(p.Prefix("Server.Hosts")), 2)
+
+	// 已经存在宽松匹配的属性时更新该属性
+	p.Set("server.maxConn", 200)
+	util.AssertEqual(t, p.Get("server.max-conn"), 200)
+	util.AssertEqual(t, len(p.Keys()), 3)
+
+	var s struct {
+		MaxConn int               `value:"${SERVER_MAXCONN}"`
+		Hosts   map[string]string `value:"${server.Hosts}"`
+	}
+	p.Bind("", &s)
+	util.AssertEqual(t, s.MaxConn, 200)
+	util.AssertEqual(t, s.Hosts, map[string]string{"host-a": "a", "host-b": "b"})
+
+	p.Set("SERVER_MAXCONN", nil)
+	util.AssertEqual(t, p.Has("server.max-conn"), false)
+}
+
+func TestBindValue_StructCollections(t *testing.T) {
+
+	type TLS struct {
+		Enabled bool `value:"${enabled:=false}"`
+	}
+
+	type Endpoint struct {
+		Host string   `value:"${host}"`
+		Port int      `value:"${port:=80}"`
+		TLS  TLS      `value:"${tls}"`
+		Tags []string `value:"${tags:=none}"`
+	}
+
+	t.Run("indexed", func(t *testing.T) {
+		p := conf.New()
+		err := p.Read(strings.NewReader(`
+servers[0].host=a.com
+servers[0].tls.enabled=true
+servers[1].host=b.com
+servers[1].port=8080
+servers[1].tags[0]=x
+servers[1].tags[1]=z
+ports[0]=80
+ports[1]=443
+`), "properties")
+		util.AssertEqual(t, err, nil)
+
+		var s struct {
+			Servers []Endpoint `value:"${servers}"`
+			Ports   []int      `value:"${ports}"`
+		}
+		util.AssertEqual(t, p.Bind("", &s), nil)
+		util.AssertEqual(t, s.Servers, []Endpoint{
+			{Host: "a.com", Port: 80, TLS: TLS{Enabled: true}, Tags: []string{"none"}},
+			{Host: "b.com", Port: 8080, Tags: []string{"x", "z"}},
+		})
+		util.AssertEqual(t, s.Ports, []int{80, 443})
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		p := conf.New()
+		err := p.Read(strings.NewReader(`
+servers:
+  - host: a.com
+    tls:
+      enabled: true
+  - host: b.com
+    port: 8080
+    tags: [x, z]
+`), "yaml")
+		util.AssertEqual(t, err, nil)
+
+		var servers []Endpoint
+		util.AssertEqual(t, p.Bind("servers", &servers), nil)
+		util.AssertEqual(t, servers, []Endpoint{
+			{Host: "a.com", Port: 80, TLS: TLS{Enabled: true}, Tags: []string{"none"}},
+			{Host: "b.com", Port: 8080, Tags: []string{"x", "z"}},
+		})
+	})
+
+	t.Run("map", func(t *testing.T) {
+		p := conf.New()
+		err := p.Read(strings.NewReader(`
+endpoints:
+  primary:
+    host: a.com
+    tls:
+      enabled: true
+  backup:
+    host: b.com
+    port: 8080
+`), "yaml")
+		util.AssertEqual(t, err, nil)
+
+		var endpoints map[string]Endpoint
+		util.AssertEqual(t, p.Bind("endpoints", &endpoints), nil)
+		util.AssertEqual(t, endpoints, map[string]Endpoint{
+			"primary": {Host: "a.com", Port: 80, TLS: TLS{Enabled: true}, Tags: []string{"none"}},
+			"backup":  {Host: "b.com", Port: 8080, Tags: []string{"none"}},
+		})
+	})
+
+	t.Run("gap", func(t *testing.T) {
+		p := conf.New()
+		p.Set("servers[1].host", "b.com")
+		var servers []Endpoint
+		err := p.Bind("servers", &servers)
+		util.AssertEqual(t, err.Error(), "property servers[0] not config")
+	})
+}
+
+func TestMetadata(t *testing.T) {
+
+	m := conf.NewMetadata()
+	m.Register(conf.PropertyMeta{Key: "server.port", Type: "int", Default: "8080", Owners: []string{"a"}})
+	m.Register(conf.PropertyMeta{Key: "server.port", Description: "服务端口", Owners: []string{"b", "a"}})
+	m.Register(conf.PropertyMeta{Key: "servers", Type: "[]Endpoint"})
+
+	meta, ok := m.Get("SERVER_PORT")
+	util.AssertEqual(t, ok, true)
+	util.AssertEqual(t, meta, conf.PropertyMeta{
+		Key:         "server.port",
+		Type:        "int",
+		Default:     "8080",
+		Description: "服务端口",
+		Owners:      []string{"a", "b"},
+	})
+
+	util.AssertEqual(t, m.Known("servers[0].host"), true)
+	util.AssertEqual(t, m.Known("servers.a"), true)
+	util.AssertEqual(t, m.Known("server.prot"), false)
+
+	var buf bytes.Buffer
+	util.AssertEqual(t, m.WriteMarkdown(&buf), nil)
+	util.AssertEqual(t, buf.String(), ""+
+		"| Key | Type | Default | Description | Owners |\n"+
+		"| --- | --- | --- | --- | --- |\n"+
+		"| `server.port` | int | 8080 | 服务端口 | `a`<br>`b` |\n"+
+		"| `servers` | []Endpoint |  |  |  |\n")
+
+	buf.Reset()
+	util.AssertEqual(t, m.WriteJSON(&buf), nil)
+	var metas []conf.PropertyMeta
+	util.AssertEqual(t, json.Unmarshal(buf.Bytes(), &metas), nil)
+	util.AssertEqual(t, metas, m.All())
+
+	t.Run("collect", func(t *testing.T) {
+		type Pool struct {
+			Size int
+		}
+		type DB struct {
+			URL     string `value:"${db.url:=localhost}"`
+			Debug   bool
+			Pool    Pool
+			Timeout time.Duration
+		}
+		m := conf.NewMetadata()
+		conf.CollectMetadata(conf.New(), reflect.TypeOf(DB{}), "db", conf.BindOption{Metadata: m, Owner: "db"})
+		var keys []string
+		for _, meta := range m.All() {
+			keys = append(keys, meta.Key+":"+meta.Type+":"+meta.Default)
+		}
+		util.AssertEqual(t, keys, []string{
+			"db.debug:bool:",
+			"db.pool.size:int:",
+			"db.timeout:time.Duration:",
+			"db.url:string:localhost",
+		})
+	})
+}
+
+func TestReadBytes(t *testing.T) {
+
+	t.Run("json", func(t *testing.T) {
+		m, err := conf.ReadBytes([]byte(`{"server": {"port": 8080, "hosts": ["a", "b"]}}`), "json")
+		util.AssertEqual(t, err, nil)
+		util.AssertEqual(t, m, map[string]interface{}{
+			"server.port":  float64(8080),
+			"server.hosts": []interface{}{"a", "b"},
+		})
+	})
+
+	t.Run("env", func(t *testing.T) {
+		m, err := conf.ReadBytes([]byte(`
+# comment
+export SERVER_PORT=8080
+DB_URL="mysql://a # b"
+DB_USER='root' 
+app.name=go # name
+`), "env")
+		util.AssertEqual(t, err, nil)
+		util.AssertEqual(t, m, map[string]interface{}{
+			"server.port": "8080",
+			"db.url":      "mysql://a # b",
+			"db.user":     "root",
+			"app.name":    "go",
+		})
+
+		_, err = conf.ReadBytes([]byte("SERVER_PORT"), "env")
+		util.AssertEqual(t, err.Error(), `invalid env line 1: "SERVER_PORT"`)
+	})
+
+	t.Run("ini", func(t *testing.T) {
+		m, err := conf.ReadBytes([]byte("[server]\nport = 8080\n"), "ini")
+		util.AssertEqual(t, err, nil)
+		util.AssertEqual(t, m, map[string]interface{}{"server.port": "8080"})
+	})
+
+	t.Run("properties", func(t *testing.T) {
+		m, err := conf.ReadBytes([]byte("url=mysql://${host}\nhost=localhost\n"), ".properties")
+		util.AssertEqual(t, err, nil)
+		util.AssertEqual(t, m, map[string]interface{}{"url": "mysql://${host}", "host": "localhost"})
+	})
+
+	t.Run("yml", func(t *testing.T) {
+		b := []byte("server:\n  port: 8080\n  hosts: [a, b]\n")
+		yaml, err := conf.ReadBytes(b, "yaml")
+		util.AssertEqual(t, err, nil)
+		yml, err := conf.ReadBytes(b, "yml")
+		util.AssertEqual(t, err, nil)
+		util.AssertEqual(t, yml, yaml)
+	})
+
+	t.Run("types", func(t *testing.T) {
+		util.AssertEqual(t, conf.ReaderTypes(), []string{"properties", "yaml", "yml", "toml", "json", "ini", "hcl", "env"})
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := conf.ReadBytes(nil, "xml")
+		util.AssertEqual(t, err.Error(), `unsupported config type "xml"`)
+	})
+
+	t.Run("sniff", func(t *testing.T) {
+		for s, expect := range map[string]string{
+			`{"a": 1}`:                   "json",
+			"---\na: 1":                  "yaml",
+			"# yaml\nserver:\n  port: 1": "yaml",
+			"- a\n- b":                   "yaml",
+			"SERVER_PORT=8080":           "env",
+			"server.port=8080":           "properties",
+			"[server]\nport = 8080":      "toml",
+			"[server]\nname = go":        "ini",
+			"":                           "properties",
+		} {
+			util.AssertEqual(t, conf.SniffType([]byte(s)), expect)
+		}
+	})
+
+	t.Run("read", func(t *testing.T) {
+		p := conf.New()
+		err := p.Read(strings.NewReader("SERVER_PORT=8080"), "")
+		util.AssertEqual(t, err, nil)
+		util.AssertEqual(t, p.Get("server.port"), "8080")
+	})
+}
+
+func TestDefaultProperties_Typed(t *testing.T) {
+
+	p := conf.New()
+	p.Set("debug", "true")
+	p.Set("port", 8080)
+	p.Set("ratio", "0.5")
+	p.Set("name", "go-spring")
+	p.Set("timeout", "1m30s")
+	p.Set("bad-timeout", "3 seconds")
+	p.Set("time", "2021-01-02")
+	p.Set("hosts", "a,b,c")
+	p.Set("tags[0]", "x")
+	p.Set("tags[1]", "y")
+	p.Set("size", "10MB")
+	p.SetOrigin("bad-timeout", &conf.Origin{File: "application.properties", Line: 3})
+
+	util.AssertEqual(t, p.MustGetBool("debug"), true)
+	util.AssertEqual(t, p.MustGetInt("port"), int64(8080))
+	util.AssertEqual(t, p.MustGetUint("port"), uint64(8080))
+	util.AssertEqual(t, p.MustGetFloat("ratio"), 0.5)
+	util.AssertEqual(t, p.MustGetString("port"), "8080")
+	util.AssertEqual(t, p.MustGetDuration("timeout"), 90*time.Second)
+	util.AssertEqual(t, p.MustGetTime("time"), time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC))
+	util.AssertEqual(t, p.MustGetStringSlice("hosts"), []string{"a", "b", "c"})
+	util.AssertEqual(t, p.MustGetStringSlice("tags"), []string{"x", "y"})
+	util.AssertEqual(t, p.MustGetSize("size"), int64(10<<20))
+
+	_, err := p.GetInt("name")
+	util.AssertEqual(t, err.Error(), "property value name isn't int type")
+
+	_, err = p.GetDuration("bad-timeout")
+	util.AssertEqual(t, err.Error(), `property value bad-timeout: time: unknown unit " seconds" in duration "3 seconds" (key: bad-timeout, origin: application.properties:3)`)
+
+	_, err = p.GetString("not-exist")
+	util.AssertEqual(t, err.Error(), "property not-exist not config")
+
+	util.AssertPanic(t, func() { p.MustGetSize("name") }, `invalid size "go-spring"`)
+}
+
+func TestParseSize(t *testing.T) {
+
+	for s, expect := range map[string]int64{
+		"512":   512,
+		"512B":  512,
+		"10KB":  10 << 10,
+		"10 mb": 10 << 20,
+		"1GiB":  1 << 30,
+		"2T":    2 << 40,
+	} {
+		size, err := conf.ParseSize(s)
+		util.AssertEqual(t, err, nil)
+		util.AssertEqual(t, size, expect)
+	}
+
+	_, err := conf.ParseSize("10XB")
+	util.AssertEqual(t, err.Error(), `invalid size unit "XB"`)
+
+	_, err = conf.ParseSize("-1")
+	util.AssertEqual(t, err.Error(), `invalid size "-1"`)
+}
+
+func TestBindValue_Converters(t *testing.T) {
+
+	p := conf.New()
+	p.Set("ip", "127.0.0.1")
+	p.Set("url", "http://go-spring.com/docs")
+	p.Set("pattern", "^go-.*$")
+	p.Set("size", "2KB")
+	p.Set("sizes", "1KB,2KB")
+	p.Set("limits.read", "1MB")
+	p.Set("limits.write", "2MB")
+	p.Set("point", "(1,2)")
+	p.Set("points", "(1,2),(3,4)")
+	p.Set("date", "2021-01-02")
+	p.Set("dates[0]", "2021-01-02")
+	p.Set("timeout", "3s")
+
+	err := p.Convert(func(s string) (image.Point, error) {
+		var x, y int
+		_, err := fmt.Sscanf(s, "(%d;%d)", &x, &y)
+		return image.Point{X: x, Y: y}, err
+	})
+	util.AssertEqual(t, err, nil)
+
+	var s struct {
+		IP      net.IP                   `value:"${ip}"`
+		URL     *url.URL                 `value:"${url}"`
+		Pattern *regexp.Regexp           `value:"${pattern}"`
+		Size    conf.ByteSize            `value:"${size}"`
+		Sizes   []conf.ByteSize          `value:"${sizes}"`
+		Limits  map[string]conf.ByteSize `value:"${limits}"`
+		Date    time.Time                `value:"${date}" layout:"2006-01-02"`
+		Dates   []time.Time              `value:"${dates}" layout:"2006-01-02"`
+		Timeout *time.Duration           `value:"${timeout}"`
+		Port    *int                     `value:"${port:=8080}"`
+	}
+	err = conf.BindStruct(p, reflect.ValueOf(&s).Elem(), conf.BindOption{})
+	util.AssertEqual(t, err, nil)
+	util.AssertEqual(t, s.IP.String(), "127.0.0.1")
+	util.AssertEqual(t, s.URL.Host, "go-spring.com")
+	util.AssertEqual(t, s.Pattern.MatchString("go-spring"), true)
+	util.AssertEqual(t, s.Size, conf.ByteSize(2048))
+	util.AssertEqual(t, s.Sizes, []conf.ByteSize{1024, 2048})
+	util.AssertEqual(t, s.Limits, map[string]conf.ByteSize{"read": 1 << 20, "write": 2 << 20})
+	util.AssertEqual(t, s.Date, time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC))
+	util.AssertEqual(t, s.Dates, []time.Time{time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)})
+	util.AssertEqual(t, *s.Timeout, 3*time.Second)
+	util.AssertEqual(t, *s.Port, 8080)
+
+	t.Run("value field", func(t *testing.T) {
+		var v struct {
+			URL      url.URL         `value:"${url}"`
+			Pattern  regexp.Regexp   `value:"${pattern}"`
+			Patterns []regexp.Regexp `value:"${patterns:=^go-,^spring}"`
+		}
+		err := conf.BindStruct(p, reflect.ValueOf(&v).Elem(), conf.BindOption{})
+		util.AssertEqual(t, err, nil)
+		util.AssertEqual(t, v.URL.Host, "go-spring.com")
+		util.AssertEqual(t, v.Pattern.MatchString("go-spring"), true)
+		util.AssertEqual(t, len(v.Patterns), 2)
+		util.AssertEqual(t, v.Patterns[1].MatchString("spring-core"), true)
+
+		var w struct {
+			URL url.URL
+		}
+		_, _, err = conf.BindPrefix(p, reflect.ValueOf(&w).Elem(), "", conf.BindOption{})
+		util.AssertEqual(t, err, nil)
+		util.AssertEqual(t, w.URL.Host, "go-spring.com")
+	})
+
+	t.Run("error", func(t *testing.T) {
+		var v struct {
+			Point  image.Point   `value:"${point}"`
+			Points []image.Point `value:"${points}"`
+			Date   time.Time     `value:"${timeout}" layout:"2006-01-02"`
+		}
+		err := conf.BindStruct(p, reflect.ValueOf(&v).Elem(), conf.BindOption{})
+		util.AssertEqual(t, err.Error(), strings.Join([]string{
+			"property value point: input does not match format",
+			"property value points[0]: unexpected EOF",
+			`property value timeout: parsing time "3s" as "2006-01-02": cannot parse "3s" as "2006"`,
+		}, "\n"))
+	})
+}
+
+func TestBindPrefix(t *testing.T) {
+
+	type Pool struct {
+		MaxSize int
+	}
+
+	type Base struct {
+		Name string
+	}
+
+	var s struct {
+		Base
+		URLPath  string
+		Timeout  time.Duration
+		Pool     Pool
+		Tags     map[string]string
+		Disabled bool
+	}
+
+	p := conf.New()
+	p.Set("server.name", "go-spring")
+	p.Set("server.url_path", "/api")
+	p.Set("server.pool.maxSize", "10")
+	p.Set("server.tags.a", "1")
+	p.Set("server.typo", "x")
+	p.Set("server.pool.min-size", "1")
+	p.Set("client.name", "other")
+
+	unknown, missing, err := conf.BindPrefix(p, reflect.ValueOf(&s).Elem(), "server", conf.BindOption{})
+	util.AssertEqual(t, err, nil)
+	util.AssertEqual(t, unknown, []string{"server.pool.min-size", "server.typo"})
+	util.AssertEqual(t, missing, []string{"server.timeout", "server.disabled"})
+	util.AssertEqual(t, s.Name, "go-spring")
+	util.AssertEqual(t, s.URLPath, "/api")
+	util.AssertEqual(t, s.Pool.MaxSize, 10)
+	util.AssertEqual(t, s.Tags, map[string]string{"a": "1"})
+
+	p.Set("server.disabled", "maybe")
+	_, _, err = conf.BindPrefix(p, reflect.ValueOf(&s).Elem(), "server", conf.BindOption{})
+	util.AssertEqual(t, err.Error(), "property value server.disabled isn't bool type")
+}
+
+func TestWriteProperties(t *testing.T) {
+
+	m := map[string]interface{}{
+		"server.port":  8080,
+		"server.hosts": []string{"a", "b"},
+		"db":           "x",
+		"db.url":       "mysql://",
+	}
+
+	var buf bytes.Buffer
+	util.AssertEqual(t, conf.WriteProperties(&buf, m, "properties"), nil)
+	util.AssertEqual(t, buf.String(), "db=x\ndb.url=mysql://\nserver.hosts[0]=a\nserver.hosts[1]=b\nserver.port=8080\n")
+
+	buf.Reset()
+	util.AssertEqual(t, conf.WriteProperties(&buf, m, ".yaml"), nil)
+	util.AssertEqual(t, buf.String(), "db: x\ndb.url: mysql://\nserver:\n  hosts:\n  - a\n  - b\n  port: 8080\n")
+
+	buf.Reset()
+	util.AssertEqual(t, conf.WriteProperties(&buf, map[string]interface{}{"a.b": "<c>"}, "json"), nil)
+	util.AssertEqual(t, buf.String(), "{\n  \"a\": {\n    \"b\": \"<c>\"\n  }\n}\n")
+
+	err := conf.WriteProperties(&buf, m, "xml")
+	util.AssertEqual(t, err.Error(), `unsupported config type "xml"`)
+}
+
+func TestDiffSnapshot(t *testing.T) {
+
+	p := conf.New()
+	p.Set("db.password", "123456")
+	p.Set("server.port", 8080)
+	s := conf.Snapshot(p)
+	util.AssertEqual(t, s["db.password"], conf.MaskedValue)
+
+	staging := map[string]interface{}{
+		"db.password":  conf.MaskedValue,
+		"server.port":  8080,
+		"server.hosts": []interface{}{"a"},
+		"debug":        true,
+	}
+	production := map[string]interface{}{
+		"db.password":     conf.MaskedValue,
+		"server.port":     "8080",
+		"server.hosts[0]": "b",
+		"log.level":       "warn",
+	}
+
+	changes := conf.DiffSnapshot(staging, production)
+	var buf bytes.Buffer
+	util.AssertEqual(t, conf.WriteDiff(&buf, changes), nil)
+	util.AssertEqual(t, buf.String(), "- debug=true\n+ log.level=warn\n~ server.hosts[0]=a -> b\n")
+}
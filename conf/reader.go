@@ -0,0 +1,206 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-spring/spring-core/log"
+	"github.com/magiconair/properties"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	RegisterReader("properties", readPropertiesFormat)
+	for _, configType := range []string{"yaml", "yml", "toml", "json", "ini", "hcl"} {
+		RegisterReader(configType, viperReader(configType))
+	}
+	RegisterReader("env", readEnv)
+}
+
+// Reader 配置读取函数，将配置内容解析成属性名到属性值的映射。
+type Reader func(b []byte) (map[string]interface{}, error)
+
+var (
+	readers     = make(map[string]Reader) // 配置读取函数集合，key 是配置类型，例如 yaml、json
+	readerTypes []string                  // 配置类型，按照注册的顺序
+)
+
+// RegisterReader 注册配置读取函数，configType 是配置类型，也是配置文件的扩展名（不含点号）。
+// 重复注册时替换原来的读取函数，但是保持原来的顺序。
+func RegisterReader(configType string, r Reader) {
+	configType = strings.ToLower(configType)
+	if _, ok := readers[configType]; !ok {
+		readerTypes = append(readerTypes, configType)
+	}
+	readers[configType] = r
+}
+
+// ReaderTypes 返回注册的配置类型，按照注册的顺序，同一目录中多个格式的配置文件按照
+// 这个顺序加载，后加载的优先级更高。
+func ReaderTypes() []string {
+	return append([]string{}, readerTypes...)
+}
+
+// GetReader 返回配置类型对应的读取函数
+func GetReader(configType string) (Reader, bool) {
+	r, ok := readers[strings.ToLower(strings.TrimPrefix(configType, "."))]
+	return r, ok
+}
+
+// ReadBytes 按照 configType 解析配置内容，configType 为空时根据内容推测配置类型。
+func ReadBytes(b []byte, configType string) (map[string]interface{}, error) {
+	if configType == "" {
+		configType = SniffType(b)
+	}
+	r, ok := GetReader(configType)
+	if !ok {
+		return nil, fmt.Errorf("unsupported config type %q", configType)
+	}
+	return r(b)
+}
+
+// readPropertiesFormat 解析 properties 格式的配置内容，属性值中的引用保持原样，由 ResolveProperty 解析。
+// 反斜杠是 properties 格式的转义字符，转义的引用需要写成 \\${key}。
+func readPropertiesFormat(b []byte) (map[string]interface{}, error) {
+	p := properties.NewProperties()
+	p.DisableExpansion = true
+	if err := p.Load(b, properties.UTF8); err != nil {
+		return nil, err
+	}
+	result := make(map[string]interface{})
+	for _, key := range p.Keys() {
+		result[key], _ = p.Get(key)
+	}
+	return result, nil
+}
+
+// viperReader 使用 viper 解析配置内容
+func viperReader(configType string) Reader {
+	return func(b []byte) (map[string]interface{}, error) {
+		v := viper.New()
+		v.SetConfigType(configType)
+		if err := v.ReadConfig(bytes.NewReader(b)); err != nil {
+			return nil, err
+		}
+		result := make(map[string]interface{})
+		for _, key := range v.AllKeys() {
+			result[key] = v.Get(key)
+		}
+		return result, nil
+	}
+}
+
+// readEnv 解析 .env 格式的配置内容，每行一个 KEY=VALUE，支持 export 前缀、
+// # 注释以及单双引号，环境变量风格的名称按照 EnvKey 转换成属性名。
+func readEnv(b []byte) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		i := strings.Index(line, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid env line %d: %q", n, line)
+		}
+		key := strings.TrimSpace(line[:i])
+		val := strings.TrimSpace(line[i+1:])
+		switch {
+		case len(val) >= 2 && val[0] == '"' && val[len(val)-1] == '"':
+			s, err := strconv.Unquote(val)
+			if err != nil {
+				return nil, fmt.Errorf("invalid env line %d: %v", n, err)
+			}
+			val = s
+		case len(val) >= 2 && val[0] == '\'' && val[len(val)-1] == '\'':
+			val = val[1 : len(val)-1]
+		default: // 没有引号时 # 之后是注释
+			if j := strings.Index(val, " #"); j >= 0 {
+				val = strings.TrimSpace(val[:j])
+			}
+		}
+		result[EnvKey(key)] = val
+	}
+	return result, scanner.Err()
+}
+
+var (
+	envLine     = regexp.MustCompile(`^(export\s+)?[A-Z_][A-Z0-9_]*\s*=`)
+	sectionLine = regexp.MustCompile(`^\[[^\]]+\]$`)
+	yamlLine    = regexp.MustCompile(`^[\w.-]+\s*:(\s|$)`)
+)
+
+// SniffType 根据内容推测配置类型，用于没有扩展名的配置，例如 ConfigMap 的条目。
+// 这只是尽力而为的推测，无法识别时返回 properties。
+func SniffType(b []byte) string {
+
+	s := strings.TrimSpace(string(b))
+	if strings.HasPrefix(s, "{") {
+		return "json"
+	}
+	if strings.HasPrefix(s, "---") {
+		return "yaml"
+	}
+
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == ';' || line[0] == '!' {
+			continue
+		}
+		switch {
+		case sectionLine.MatchString(line):
+			// toml 和 ini 都有分组，能按照 toml 解析就是 toml
+			if _, err := viperReader("toml")(b); err == nil {
+				return "toml"
+			}
+			return "ini"
+		case envLine.MatchString(line):
+			return "env"
+		case yamlLine.MatchString(line), strings.HasPrefix(line, "- "):
+			return "yaml"
+		}
+		return "properties"
+	}
+	return "properties"
+}
+
+// readProperties 使用 Reader 读取配置内容并按照属性名的顺序设置属性值
+func (p *defaultProperties) readProperties(b []byte, configType string) error {
+	m, err := ReadBytes(b, configType)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		p.Set(key, m[key])
+		log.Tracef("%s=%v", key, MaskValue(p, key, m[key]))
+	}
+	return nil
+}
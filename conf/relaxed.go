@@ -0,0 +1,94 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"strings"
+)
+
+// CanonicalKey 返回属性名的规范形式，规范形式相同的属性名被当作同一个属性，这样
+// server.max-conn、server.maxConn、server.max_conn 和 SERVER_MAXCONN 都对应
+// 同一个属性 server.maxconn。规范形式的规则如下:
+//
+//  1. 全部是大写字母、数字和下划线的名称被当作环境变量，下划线先转换成点号；
+//  2. 转换成小写；
+//  3. 删除每一段中的短横线和下划线。
+//
+// 注意环境变量中的下划线只能表示层级，因此 SERVER_MAX_CONN 对应 server.max.conn。
+// EnvName 和 EnvKey 使用同一个约定，EnvKey(EnvName(key)) 和 key 的规范形式相同。
+// 同一属性层中多个写法对应同一个属性时，按照属性名排序后设置，后面的值覆盖前面的
+// 值，并且输出一条警告。
+func CanonicalKey(key string) string {
+	if isEnvStyle(key) {
+		key = strings.Replace(key, "_", ".", -1)
+	}
+	key = strings.ToLower(key)
+	return strings.NewReplacer("-", "", "_", "").Replace(key)
+}
+
+// EnvKey 返回环境变量对应的属性名，环境变量风格的名称中的下划线转换成点号，
+// 例如 SPRING_PROFILE 对应 spring.profile，其他名称只转换成小写。
+func EnvKey(name string) string {
+	if isEnvStyle(name) {
+		name = strings.Replace(name, "_", ".", -1)
+	}
+	return strings.ToLower(name)
+}
+
+// isEnvStyle 返回是否是环境变量风格的名称，即只包含大写字母、数字和下划线。
+func isEnvStyle(key string) bool {
+	upper := false
+	for _, c := range key {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= '0' && c <= '9', c == '_':
+		default:
+			return false
+		}
+	}
+	return upper
+}
+
+// trimKeyPrefix 删除属性名的前缀 prefix，前缀和属性名的写法可以不同，例如
+// trimKeyPrefix("server.max-conn.a", "server.maxConn") 返回 "a"。
+func trimKeyPrefix(key string, prefix string) string {
+
+	if prefix == "" {
+		return key
+	}
+
+	if strings.HasPrefix(key, prefix+".") {
+		return key[len(prefix)+1:]
+	}
+
+	// 写法不同时按照层级删除，规范形式不会改变层级
+	n := strings.Count(CanonicalKey(prefix), ".") + 1
+	if ss := strings.SplitN(key, ".", n+1); len(ss) > n {
+		return ss[n]
+	}
+	return key
+}
+
+// hasKeyPrefix 返回属性名 key 是否等于 prefix 或者以 prefix 为前缀，写法可以不同。
+func hasKeyPrefix(key string, prefix string) bool {
+	if key == prefix || strings.HasPrefix(key, prefix+".") {
+		return true
+	}
+	ck, cp := CanonicalKey(key), CanonicalKey(prefix)
+	return ck == cp || strings.HasPrefix(ck, cp+".")
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+)
+
+// Snapshot 返回所有属性值的快照，敏感信息会被脱敏，可以使用 WriteProperties 输出。
+func Snapshot(p Properties) map[string]interface{} {
+	m := make(map[string]interface{})
+	p.Fill(m)
+	for k, v := range m {
+		m[k] = MaskValue(p, k, v)
+	}
+	return m
+}
+
+// ReadSnapshot 读取快照文件，按照扩展名选择配置类型。
+func ReadSnapshot(filename string) (map[string]interface{}, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ReadBytes(b, filepath.Ext(filename))
+}
+
+// PropertyChange 两个快照之间一个属性的差异，新增的属性 Old 为空，删除的属性 New 为空。
+type PropertyChange struct {
+	Key string
+	Old *string
+	New *string
+}
+
+func (c PropertyChange) String() string {
+	switch {
+	case c.Old == nil:
+		return fmt.Sprintf("+ %s=%s", c.Key, *c.New)
+	case c.New == nil:
+		return fmt.Sprintf("- %s=%s", c.Key, *c.Old)
+	default:
+		return fmt.Sprintf("~ %s=%s -> %s", c.Key, *c.Old, *c.New)
+	}
+}
+
+// DiffSnapshot 按照属性名的顺序返回两个快照之间的差异。属性值展开成字符串进行比较，
+// 因此不同格式的快照之间也可以比较，脱敏的属性值总是相同的。
+func DiffSnapshot(oldSnapshot, newSnapshot map[string]interface{}) []PropertyChange {
+
+	oldValues := flattenProperties(oldSnapshot)
+	newValues := flattenProperties(newSnapshot)
+
+	var keys []string
+	for k := range oldValues {
+		keys = append(keys, k)
+	}
+	for k := range newValues {
+		if _, ok := oldValues[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var changes []PropertyChange
+	for _, k := range keys {
+		oldValue, inOld := oldValues[k]
+		newValue, inNew := newValues[k]
+		switch {
+		case !inOld:
+			changes = append(changes, PropertyChange{Key: k, New: &newValue})
+		case !inNew:
+			changes = append(changes, PropertyChange{Key: k, Old: &oldValue})
+		case oldValue != newValue:
+			changes = append(changes, PropertyChange{Key: k, Old: &oldValue, New: &newValue})
+		}
+	}
+	return changes
+}
+
+// WriteDiff 逐行输出快照之间的差异，+ 表示新增，- 表示删除，~ 表示修改。
+func WriteDiff(w io.Writer, changes []PropertyChange) error {
+	for _, c := range changes {
+		if _, err := fmt.Fprintln(w, c.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
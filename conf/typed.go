@@ -0,0 +1,144 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// getTypedValue 获取属性值并转换成 i 指向的类型，和 BindValue 使用相同的转换逻辑，
+// 属性不存在或者无法转换时返回错误。
+func getTypedValue(p Properties, key string, i interface{}) error {
+	v := reflect.ValueOf(i).Elem()
+
+	exist := p.Get(key) != nil
+	if !exist && v.Kind() == reflect.Slice {
+		indexedValue, err := getIndexedValue(p, key)
+		if err != nil {
+			return err
+		}
+		exist = len(indexedValue) > 0
+	}
+	if !exist {
+		return fmt.Errorf("property %s not config", key)
+	}
+
+	if err := BindValue(p, v, key, nil, BindOption{FieldName: key, FullName: key}); err != nil {
+		if origin := p.Origin(key); origin != nil {
+			return &BindError{Key: key, Origin: origin, Err: err}
+		}
+		return err
+	}
+	return nil
+}
+
+// getBool 返回布尔类型的属性值
+func getBool(p Properties, key string) (b bool, err error) {
+	err = getTypedValue(p, key, &b)
+	return
+}
+
+// getInt 返回整数类型的属性值
+func getInt(p Properties, key string) (i int64, err error) {
+	err = getTypedValue(p, key, &i)
+	return
+}
+
+// getUint 返回无符号整数类型的属性值
+func getUint(p Properties, key string) (u uint64, err error) {
+	err = getTypedValue(p, key, &u)
+	return
+}
+
+// getFloat 返回浮点类型的属性值
+func getFloat(p Properties, key string) (f float64, err error) {
+	err = getTypedValue(p, key, &f)
+	return
+}
+
+// getString 返回字符串类型的属性值
+func getString(p Properties, key string) (s string, err error) {
+	err = getTypedValue(p, key, &s)
+	return
+}
+
+// getDuration 返回时长类型的属性值
+func getDuration(p Properties, key string) (d time.Duration, err error) {
+	err = getTypedValue(p, key, &d)
+	return
+}
+
+// getTime 返回时间类型的属性值
+func getTime(p Properties, key string) (t time.Time, err error) {
+	err = getTypedValue(p, key, &t)
+	return
+}
+
+// getStringSlice 返回字符串切片类型的属性值，字符串按照逗号进行切割。
+func getStringSlice(p Properties, key string) (s []string, err error) {
+	err = getTypedValue(p, key, &s)
+	return
+}
+
+// getSize 返回字节数，支持 10MB 这样带单位的形式，参见 ParseSize。
+func getSize(p Properties, key string) (int64, error) {
+	var size ByteSize
+	err := getTypedValue(p, key, &size)
+	return int64(size), err
+}
+
+// sizeUnits 字节数单位，采用 1024 进制，KB 和 KiB 等价。
+var sizeUnits = map[string]int64{
+	"":  1,
+	"b": 1,
+	"k": 1 << 10, "kb": 1 << 10, "kib": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20, "mib": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30, "gib": 1 << 30,
+	"t": 1 << 40, "tb": 1 << 40, "tib": 1 << 40,
+}
+
+// sizeRegexp 数字和单位之间可以有空格
+var sizeRegexp = regexp.MustCompile(`^(\d+)\s*([a-zA-Z]*)$`)
+
+// ParseSize 解析字节数，例如 512、10KB、10MB、1GiB，单位不区分大小写。
+func ParseSize(s string) (int64, error) {
+	ss := sizeRegexp.FindStringSubmatch(strings.TrimSpace(s))
+	if ss == nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	unit, ok := sizeUnits[strings.ToLower(ss[2])]
+	if !ok {
+		return 0, fmt.Errorf("invalid size unit %q", ss[2])
+	}
+	n, err := strconv.ParseInt(ss[1], 10, 64)
+	if err != nil || n > (1<<63-1)/unit {
+		return 0, fmt.Errorf("size %q overflows", s)
+	}
+	return n * unit, nil
+}
+
+// mustValue 获取属性值失败时 panic
+func mustValue(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
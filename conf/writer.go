@@ -0,0 +1,170 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	RegisterWriter("properties", writeProperties)
+	RegisterWriter("yaml", writeYaml)
+	RegisterWriter("yml", writeYaml)
+	RegisterWriter("json", writeJson)
+}
+
+// Writer 配置输出函数，将属性名到属性值的映射按照某种配置格式输出。
+type Writer func(w io.Writer, properties map[string]interface{}) error
+
+// writers 配置输出函数集合，key 是配置类型，例如 yaml、json。
+var writers = make(map[string]Writer)
+
+// RegisterWriter 注册配置输出函数，configType 是配置类型，也是配置文件的扩展名（不含点号）。
+func RegisterWriter(configType string, w Writer) {
+	writers[strings.ToLower(configType)] = w
+}
+
+// GetWriter 返回配置类型对应的输出函数
+func GetWriter(configType string) (Writer, bool) {
+	w, ok := writers[strings.ToLower(strings.TrimPrefix(configType, "."))]
+	return w, ok
+}
+
+// WriteProperties 按照 configType 输出属性值
+func WriteProperties(w io.Writer, properties map[string]interface{}, configType string) error {
+	fn, ok := GetWriter(configType)
+	if !ok {
+		return fmt.Errorf("unsupported config type %q", configType)
+	}
+	return fn(w, properties)
+}
+
+// sortedKeys 返回排序后的属性名
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// flattenValue 将属性值展开成 key=value 的形式，切片展开成 key[i]，map 展开成 key.sub。
+func flattenValue(key string, v interface{}, result map[string]string) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if _, ok := v.([]byte); ok {
+			break
+		}
+		for i := 0; i < rv.Len(); i++ {
+			flattenValue(fmt.Sprintf("%s[%d]", key, i), rv.Index(i).Interface(), result)
+		}
+		return
+	case reflect.Map:
+		for _, mk := range rv.MapKeys() {
+			flattenValue(key+"."+fmt.Sprint(mk.Interface()), rv.MapIndex(mk).Interface(), result)
+		}
+		return
+	}
+	result[key] = fmt.Sprint(v)
+}
+
+// flattenProperties 将所有属性值展开成字符串形式，以便输出和比较。
+func flattenProperties(properties map[string]interface{}) map[string]string {
+	result := make(map[string]string)
+	for k, v := range properties {
+		flattenValue(k, v, result)
+	}
+	return result
+}
+
+// writeProperties 按照属性名的顺序输出 properties 格式，切片展开成 key[i] 的形式。
+func writeProperties(w io.Writer, properties map[string]interface{}) error {
+	m := flattenProperties(properties)
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := strings.Replace(m[k], "\n", "\\n", -1)
+		if _, err := fmt.Fprintf(w, "%s=%s\n", k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nestProperties 将多级属性名转换成嵌套的 map，已经是属性值的层级不能再嵌套，
+// 这时剩余的属性名保留点号，例如 a=1 和 a.b=2 转换成 {a:1, a.b:2}。
+func nestProperties(properties map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, k := range sortedKeys(properties) {
+		m := result
+		ss := strings.Split(k, ".")
+		for i, s := range ss {
+			if i == len(ss)-1 {
+				m[s] = properties[k]
+				break
+			}
+			if sub, ok := m[s]; ok {
+				if subMap, ok := sub.(map[string]interface{}); ok {
+					m = subMap
+					continue
+				}
+				m[strings.Join(ss[i:], ".")] = properties[k]
+				break
+			}
+			subMap := make(map[string]interface{})
+			m[s] = subMap
+			m = subMap
+		}
+	}
+	return result
+}
+
+// writeYaml 输出嵌套的 yaml 格式
+func writeYaml(w io.Writer, properties map[string]interface{}) error {
+	b, err := yaml.Marshal(nestProperties(properties))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// writeJson 输出嵌套的 json 格式
+func writeJson(w io.Writer, properties map[string]interface{}) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(nestProperties(properties)); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
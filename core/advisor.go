@@ -0,0 +1,157 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+
+	"github.com/go-spring/spring-core/bean"
+	"github.com/go-spring/spring-core/util"
+)
+
+// Advisor 拦截器的注册信息，决定拦截器作用于哪些 Bean 以及执行顺序。
+type Advisor struct {
+	interceptor bean.Interceptor
+	order       int                     // 执行顺序，值越小越先执行
+	selectors   []bean.BeanSelector     // 匹配的 Bean
+	predicate   func(reflect.Type) bool // 匹配的接口类型
+}
+
+// newAdvisor Advisor 的构造函数
+func newAdvisor(interceptor bean.Interceptor) *Advisor {
+	if interceptor == nil {
+		panic(errors.New("interceptor can't be nil"))
+	}
+	return &Advisor{interceptor: interceptor}
+}
+
+// Order 设置拦截器的执行顺序，值越小越先执行，相同时按照注册顺序执行。
+func (a *Advisor) Order(order int) *Advisor {
+	a.order = order
+	return a
+}
+
+// On 设置拦截器作用于哪些 Bean
+func (a *Advisor) On(selectors ...bean.BeanSelector) *Advisor {
+	a.selectors = append(a.selectors, selectors...)
+	return a
+}
+
+// OnType 设置拦截器作用于哪些接口类型，即按照什么接口类型注入的时候需要拦截。
+func (a *Advisor) OnType(predicate func(t reflect.Type) bool) *Advisor {
+	a.predicate = predicate
+	return a
+}
+
+// checkAdvisors 检查所有的拦截器都设置了作用范围，在 AutoWireBeans 开始时执行，
+// 以免没有按照接口类型注入的 Bean 时错误被忽略。
+func (ctx *applicationContext) checkAdvisors() {
+	for _, a := range ctx.advisors {
+		if len(a.selectors) == 0 && a.predicate == nil {
+			panic(errors.New("interceptor should be set On or OnType"))
+		}
+	}
+}
+
+// matches 返回拦截器是否作用于以 t 接口类型注入的 bd
+func (a *Advisor) matches(bd *bean.BeanDefinition, t reflect.Type) bool {
+
+	if a.predicate != nil && !a.predicate(t) {
+		return false
+	}
+
+	if len(a.selectors) == 0 {
+		return true
+	}
+
+	for _, selector := range a.selectors {
+		switch s := selector.(type) {
+		case *bean.BeanDefinition:
+			if s == bd {
+				return true
+			}
+		case string:
+			tag := bean.ParseSingletonTag(s)
+			if bd.Match(tag.TypeName, tag.BeanName) {
+				return true
+			}
+		default:
+			st, ok := s.(reflect.Type)
+			if !ok {
+				st = util.Indirect(reflect.TypeOf(s))
+			}
+			if bd.Type() == st || bd.Type() == reflect.PtrTo(st) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// proxyKey 代理对象缓存的键
+type proxyKey struct {
+	bd  *bean.BeanDefinition
+	typ reflect.Type
+}
+
+// interceptorChain 返回作用于以 t 接口类型注入的 bd 的拦截器链
+func (ctx *applicationContext) interceptorChain(bd *bean.BeanDefinition, t reflect.Type) []bean.Interceptor {
+
+	var advisors []*Advisor
+	for _, a := range ctx.advisors {
+		if a.matches(bd, t) {
+			advisors = append(advisors, a)
+		}
+	}
+
+	sort.SliceStable(advisors, func(i, j int) bool {
+		return advisors[i].order < advisors[j].order
+	})
+
+	chain := make([]bean.Interceptor, len(advisors))
+	for i, a := range advisors {
+		chain[i] = a.interceptor
+	}
+	return chain
+}
+
+// proxyValue 返回 bd 以 t 类型注入时使用的值，t 是接口类型并且存在匹配
+// 的拦截器时返回代理对象，同一个 Bean 同一个接口类型只创建一次代理对象。
+func (ctx *applicationContext) proxyValue(bd *bean.BeanDefinition, t reflect.Type) reflect.Value {
+
+	if t.Kind() != reflect.Interface || len(ctx.advisors) == 0 {
+		return bd.Value()
+	}
+
+	key := proxyKey{bd: bd, typ: t}
+	if v, ok := ctx.proxies[key]; ok {
+		return v
+	}
+
+	v := bd.Value()
+	if chain := ctx.interceptorChain(bd, t); len(chain) > 0 {
+		var ok bool
+		if v, ok = bean.NewProxy(t, bd.Value(), chain); !ok {
+			panic(errors.New("no proxy factory for interface " + t.String()))
+		}
+	}
+
+	ctx.proxies[key] = v
+	return v
+}
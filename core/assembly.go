@@ -98,8 +98,14 @@ func (assembly *defaultBeanAssembly) Matches(cond bean.Condition) bool {
 	return cond.Matches(assembly.appCtx)
 }
 
-// BindStructField 对结构体的字段进行属性绑定
+// BindStructField 对结构体的字段进行属性绑定，同时记录属性的元数据，正在注入的 Bean 是属性的使用者。
 func (assembly *defaultBeanAssembly) BindStructField(v reflect.Value, str string, opt conf.BindOption) error {
+	if opt.Metadata == nil {
+		opt.Metadata = assembly.appCtx.metadata
+		if e := assembly.wiringStack.stack.Back(); e != nil {
+			opt.Owner = e.Value.(bean.SBeanDefinition).BeanId()
+		}
+	}
 	return conf.BindStructField(assembly.appCtx.properties, v, str, opt)
 }
 
@@ -193,7 +199,7 @@ func (assembly *defaultBeanAssembly) getBeanValue(v reflect.Value, tag bean.Sing
 	assembly.wireBeanDefinition(result, false)
 
 	v0 := util.PatchValue(v, true)
-	v0.Set(result.Value())
+	v0.Set(assembly.appCtx.proxyValue(result, beanType))
 	return true
 }
 
@@ -291,7 +297,7 @@ func (assembly *defaultBeanAssembly) collectAndSortBeans(t reflect.Type, et refl
 		}
 
 		if i := assembly.findBeanFromCache(beans, item, et); i >= 0 {
-			v := beans[i].Value()
+			v := assembly.appCtx.proxyValue(beans[i], et)
 			beans = append(beans[:i], beans[i+1:]...)
 			if foundAny {
 				afterAny = reflect.Append(afterAny, v)
@@ -303,7 +309,7 @@ func (assembly *defaultBeanAssembly) collectAndSortBeans(t reflect.Type, et refl
 
 	if foundAny {
 		for _, d := range beans {
-			any = reflect.Append(any, d.Value())
+			any = reflect.Append(any, assembly.appCtx.proxyValue(d, et))
 		}
 	}
 
@@ -349,7 +355,7 @@ func (assembly *defaultBeanAssembly) autoCollectBeans(t reflect.Type, et reflect
 
 		// 对找到的 Bean 进行自动注入
 		assembly.wireBeanDefinition(d, false)
-		result = reflect.Append(result, d.Value())
+		result = reflect.Append(result, assembly.appCtx.proxyValue(d, et))
 	}
 
 	return result // TODO 当收集接口类型的 Bean 时对于没有显式导出接口的 Bean 是否也需要收集？
@@ -409,6 +415,11 @@ func (assembly *defaultBeanAssembly) wireBeanDefinition(bd bean.SBeanDefinition,
 
 	bd.SetStatus(bean.BeanStatus_Wiring)
 
+	// 只跟踪真正的 Bean，结构体字段和数组元素的注入计入所属 Bean 的时间
+	if b, ok := bd.(*bean.BeanDefinition); ok {
+		defer assembly.appCtx.tracer.begin(TracePhase_Wire, b.BeanId()).end()
+	}
+
 	// 首先对当前 Bean 的间接依赖项进行自动注入
 	for _, selector := range bd.GetDependsOn() {
 		if b, ok := assembly.appCtx.FindBean(selector); !ok {
@@ -445,11 +456,14 @@ func (assembly *defaultBeanAssembly) wireBeanDefinition(bd bean.SBeanDefinition,
 		panic(errors.New("error spring bean type"))
 	}
 
+	// 按照 Bean 设置的前缀绑定属性值，初始化函数可以使用绑定的属性值
+	if b, ok := bd.(*bean.BeanDefinition); ok && b.GetConfigurationPrefix() != "" {
+		assembly.bindPrefix(b)
+	}
+
 	// 如果用户设置了初始化函数则执行初始化函数
 	if init := bd.GetInit(); init != nil {
-		if err := init.Run(assembly); err != nil {
-			panic(err)
-		}
+		assembly.runInit(bd, init)
 	}
 
 	// 设置为已注入状态
@@ -459,6 +473,44 @@ func (assembly *defaultBeanAssembly) wireBeanDefinition(bd bean.SBeanDefinition,
 	assembly.wiringStack.popBack()
 }
 
+// bindPrefix 按照 Bean 设置的前缀绑定属性值，报告前缀下未知的属性以及没有配置的字段，
+// Bean 设置了可刷新时属性值变化后重新绑定。
+func (assembly *defaultBeanAssembly) bindPrefix(bd *bean.BeanDefinition) {
+
+	prefix := bd.GetConfigurationPrefix()
+	opt := conf.BindOption{
+		FieldName: bd.TypeName(),
+		Metadata:  assembly.appCtx.metadata,
+		Owner:     bd.BeanId(),
+	}
+
+	v := bd.Value().Elem()
+	unknown, missing, err := conf.BindPrefix(assembly.appCtx.properties, v, prefix, opt)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, key := range unknown {
+		log.Warnf("unknown property %s for bean %s", key, bd.BeanId())
+	}
+	for _, key := range missing {
+		log.Infof("property %s for bean %s not config, use default value", key, bd.BeanId())
+	}
+
+	if bd.IsRefreshable() {
+		opt.Metadata = nil // 重新绑定时不需要记录元数据
+		assembly.appCtx.addRefreshablePrefix(v, prefix, opt)
+	}
+}
+
+// runInit 执行 Bean 的初始化函数，初始化函数 panic 时也能结束跟踪
+func (assembly *defaultBeanAssembly) runInit(bd bean.SBeanDefinition, init *bean.Runnable) {
+	defer assembly.appCtx.tracer.begin(TracePhase_Init, bd.BeanId()).end()
+	if err := init.Run(assembly); err != nil {
+		panic(err)
+	}
+}
+
 // wireObjectBean 对原始对象进行注入
 func (assembly *defaultBeanAssembly) wireObjectBean(bd bean.SBeanDefinition, onlyAutoWire bool) {
 	st := bd.Type()
@@ -492,6 +544,9 @@ func (assembly *defaultBeanAssembly) wireObjectBean(bd bean.SBeanDefinition, onl
 			sv := bd.Value()
 			ev := sv.Elem()
 
+			// 收集所有字段的属性绑定错误，以便一次性报告
+			var bindErrors conf.BindErrors
+
 			// 遍历 Bean 的每个字段，按照 tag 进行注入
 			for i := 0; i < et.NumField(); i++ {
 
@@ -506,8 +561,13 @@ func (assembly *defaultBeanAssembly) wireObjectBean(bd bean.SBeanDefinition, onl
 				if !onlyAutoWire { // 防止 value 再次解析
 					if tag, ok := ft.Tag.Lookup("value"); ok {
 						fieldOnlyAutoWire = true
-						err := assembly.BindStructField(fv, tag, conf.BindOption{FieldName: fieldName})
-						util.Panic(err).When(err != nil)
+						opt := conf.BindOption{FieldName: fieldName, Validate: ft.Tag.Get("validate"), Layout: ft.Tag.Get("layout")}
+						if err := assembly.BindStructField(fv, tag, opt); err != nil {
+							bindErrors = append(bindErrors, err)
+						} else if ft.Tag.Get("refresh") == "true" {
+							// 标记为可刷新的字段在属性值变化时重新绑定
+							assembly.appCtx.addRefreshable(util.PatchValue(fv, true), tag, opt)
+						}
 					}
 				}
 
@@ -537,6 +597,14 @@ func (assembly *defaultBeanAssembly) wireObjectBean(bd bean.SBeanDefinition, onl
 					}
 				}
 			}
+
+			switch len(bindErrors) {
+			case 0:
+			case 1:
+				panic(bindErrors[0])
+			default:
+				panic(bindErrors)
+			}
 		}
 	}
 }
@@ -614,7 +682,7 @@ func (assembly *defaultBeanAssembly) WireStructField(v reflect.Value, tag string
 	if strings.HasPrefix(tag, "${") {
 		s := ""
 		sv := reflect.ValueOf(&s).Elem()
-		err := conf.BindStructField(assembly.appCtx.properties, sv, tag, conf.BindOption{})
+		err := assembly.BindStructField(sv, tag, conf.BindOption{})
 		util.Panic(err).When(err != nil)
 		tag = s
 	}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"strings"
 	"sync"
 
 	"github.com/go-spring/spring-core/bean"
@@ -71,15 +72,26 @@ type applicationContext struct {
 	autoWired bool   // 是否开始自动绑定
 
 	AllBeans        []*bean.BeanDefinition           // 所有注册点
+	factories       []*beanFactory                   // Bean 工厂函数集合
+	replacements    []*beanReplacement               // Bean 替换列表
 	beanMap         map[beanKey]*bean.BeanDefinition // Bean 集合
 	beanCacheByName map[string]*beanCacheItem
 	beanCacheByType map[reflect.Type]*beanCacheItem
 
+	advisors []*Advisor                 // 拦截器集合
+	proxies  map[proxyKey]reflect.Value // 代理对象缓存
+
 	configers    *list.List // 配置方法集合
 	destroyers   *list.List // 销毁函数集合
 	destroyerMap map[beanKey]*destroyer
 
 	properties conf.Properties // 属性值列表接口
+	metadata   *conf.Metadata  // 属性的元数据
+
+	refreshMutex sync.Mutex
+	refreshables []*refreshable // 可刷新的属性绑定字段
+
+	tracer *Tracer // 生命周期跟踪器，为 nil 时不跟踪
 }
 
 // NewApplicationContext applicationContext 的构造函数
@@ -89,28 +101,33 @@ func NewApplicationContext() *applicationContext {
 		ctx:             ctx,
 		cancel:          cancel,
 		properties:      conf.New(),
+		metadata:        conf.NewMetadata(),
 		AllBeans:        make([]*bean.BeanDefinition, 0),
 		beanMap:         make(map[beanKey]*bean.BeanDefinition),
 		beanCacheByName: make(map[string]*beanCacheItem),
 		beanCacheByType: make(map[reflect.Type]*beanCacheItem),
+		proxies:         make(map[proxyKey]reflect.Value),
 		configers:       list.New(),
 		destroyers:      list.New(),
 		destroyerMap:    make(map[beanKey]*destroyer),
 	}
 }
 
-// LoadProperties 加载属性配置，支持 properties、yaml 和 toml 三种文件格式。
+// LoadProperties 加载属性配置，按照扩展名选择配置类型，支持 properties、yaml、toml、json、ini、env 等格式。
 func (ctx *applicationContext) LoadProperties(filename string) error {
 	return ctx.properties.Load(filename)
 }
 
-// ReadProperties 读取属性配置，支持 properties、yaml 和 toml 三种文件格式。
+// ReadProperties 读取属性配置，configType 为空时根据内容推测配置类型，参见 conf.RegisterReader。
 func (ctx *applicationContext) ReadProperties(reader io.Reader, configType string) error {
 	return ctx.properties.Read(reader, configType)
 }
 
-// BindProperty 根据类型获取属性值，属性名称统一转成小写。
+// BindProperty 根据类型获取属性值，属性名称统一转成小写，同时记录属性的元数据。
 func (ctx *applicationContext) BindProperty(key string, i interface{}) error {
+	if t := reflect.TypeOf(i); t != nil && t.Kind() == reflect.Ptr {
+		ctx.metadata.Register(conf.PropertyMeta{Key: key, Type: t.Elem().String()})
+	}
 	return ctx.properties.Bind(key, i)
 }
 
@@ -139,17 +156,49 @@ func (ctx *applicationContext) Properties() conf.Properties {
 	return ctx.properties
 }
 
+// Metadata 返回属性的元数据
+func (ctx *applicationContext) Metadata() *conf.Metadata {
+	return ctx.metadata
+}
+
+// CollectMetadata 根据已经注册的 Bean 定义收集属性的元数据，不需要进行注入。
+func (ctx *applicationContext) CollectMetadata() {
+	for _, bd := range ctx.AllBeans {
+		t := bd.Type()
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		opt := conf.BindOption{
+			FieldName: bd.TypeName(),
+			Metadata:  ctx.metadata,
+			Owner:     bd.BeanId(),
+		}
+		conf.CollectMetadata(ctx.properties, t, bd.GetConfigurationPrefix(), opt)
+	}
+}
+
 // Context 返回上下文接口
 func (ctx *applicationContext) Context() context.Context {
 	return ctx.ctx
 }
 
-// GetProfile 返回运行环境
+// GetProfile 返回运行环境，多个运行环境之间使用逗号分隔
 func (ctx *applicationContext) GetProfile() string {
 	return ctx.profile
 }
 
-// Profile 设置运行环境
+// GetProfiles 返回按照声明顺序排列的所有运行环境
+func (ctx *applicationContext) GetProfiles() []string {
+	var profiles []string
+	for _, s := range strings.Split(ctx.profile, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			profiles = append(profiles, s)
+		}
+	}
+	return profiles
+}
+
+// Profile 设置运行环境，多个运行环境之间使用逗号分隔，后面的优先级更高
 func (ctx *applicationContext) Profile(profile string) {
 	ctx.profile = profile
 }
@@ -190,6 +239,118 @@ func (ctx *applicationContext) RegisterBean(bd *bean.BeanDefinition) *bean.BeanD
 	return bd
 }
 
+// beanReplacement 待替换的 Bean
+type beanReplacement struct {
+	selector bean.BeanSelector
+	bd       *bean.BeanDefinition
+}
+
+// ReplaceBean 使用 bd 替换 selector 选中的 Bean
+func (ctx *applicationContext) ReplaceBean(selector bean.BeanSelector, bd *bean.BeanDefinition) {
+	ctx.checkRegistration()
+	ctx.replacements = append(ctx.replacements, &beanReplacement{selector, bd})
+}
+
+// matchSelector 返回 bd 是否被 selector 选中，成员方法 Bean 此时尚不能确定类型，因此不会被选中。
+func matchSelector(bd *bean.BeanDefinition, selector bean.BeanSelector) bool {
+
+	if _, ok := bd.SpringBean().(*bean.FakeMethodBean); ok {
+		return false
+	}
+
+	switch s := selector.(type) {
+	case *bean.BeanDefinition:
+		return s == bd
+	case string:
+		tag := bean.ParseSingletonTag(s)
+		return bd.Match(tag.TypeName, tag.BeanName)
+	default:
+		t, ok := s.(reflect.Type)
+		if !ok {
+			t = reflect.TypeOf(s)
+			if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Interface {
+				t = t.Elem() // 接口类型去掉指针
+			}
+		}
+		if bd.Type() == t {
+			return true
+		}
+		_, ok = bd.Exports[t]
+		return ok
+	}
+}
+
+// replaceBeans 使用替换列表替换已注册的 Bean
+func (ctx *applicationContext) replaceBeans() {
+	for _, r := range ctx.replacements {
+
+		found := false
+		for i, bd := range ctx.AllBeans {
+			if !matchSelector(bd, r.selector) {
+				continue
+			}
+
+			if found {
+				panic(fmt.Errorf("found more than one bean to replace: \"%v\"", r.selector))
+			}
+			found = true
+
+			r.bd.WithName(bd.Name())
+			r.bd.SetPrimary(bd.Primary)
+			for t := range bd.Exports {
+				if r.bd.Type().Implements(t) {
+					r.bd.Export(t)
+				}
+			}
+
+			log.Debugf("replace bean %s with %s", bd.Description(), r.bd.Description())
+			ctx.AllBeans[i] = r.bd
+		}
+
+		if !found {
+			panic(fmt.Errorf("can't find bean to replace: \"%v\"", r.selector))
+		}
+	}
+}
+
+// RegisterBeanFactory 注册一个根据属性配置批量生成 Bean 定义的工厂函数
+func (ctx *applicationContext) RegisterBeanFactory(fn interface{}, tag ...string) {
+	ctx.checkRegistration()
+	ctx.factories = append(ctx.factories, newBeanFactory(fn, tag))
+}
+
+// Intercept 注册一个方法拦截器
+func (ctx *applicationContext) Intercept(interceptor bean.Interceptor) *Advisor {
+	ctx.checkRegistration()
+	a := newAdvisor(interceptor)
+	ctx.advisors = append(ctx.advisors, a)
+	return a
+}
+
+// SetTracer 设置生命周期跟踪器，需要在 AutoWireBeans 之前调用。
+func (ctx *applicationContext) SetTracer(tracer *Tracer) {
+	ctx.checkRegistration()
+	ctx.tracer = tracer
+}
+
+// runBeanFactories 执行 Bean 工厂函数，并将生成的 Bean 添加到注册列表中
+func (ctx *applicationContext) runBeanFactories() {
+	for _, f := range ctx.factories {
+		result, err := f.create(ctx.properties, ctx.metadata)
+		if err != nil {
+			_, _, fnName := util.FileLine(f.fn)
+			panic(fmt.Errorf("bean factory: \"%s\" return error: %v", fnName, err))
+		}
+		for i, bd := range result {
+			if bd == nil {
+				_, _, fnName := util.FileLine(f.fn)
+				panic(fmt.Errorf("bean factory: \"%s\" return nil bean definition at %d", fnName, i))
+			}
+		}
+		ctx.AllBeans = append(ctx.AllBeans, result...)
+	}
+}
+
 // GetBean 获取单例 Bean，若多于 1 个则 panic；找到返回 true 否则返回 false。
 // 它和 FindBean 的区别是它在调用后能够保证返回的 Bean 已经完成了注入和绑定过程。
 func (ctx *applicationContext) GetBean(i interface{}, selector ...bean.BeanSelector) bool {
@@ -391,6 +552,8 @@ func (ctx *applicationContext) resolveBean(bd *bean.BeanDefinition) {
 
 	bd.SetStatus(bean.BeanStatus_Resolving)
 
+	defer ctx.tracer.begin(TracePhase_Resolve, bd.BeanId()).end()
+
 	// 如果是成员方法 Bean，需要首先决议它的父 Bean 是否能实例化
 	if b, ok := bd.SpringBean().(*bean.MethodBean); ok {
 
@@ -568,6 +731,15 @@ func (ctx *applicationContext) AutoWireBeans() {
 		panic(errors.New("AutoWireBeans already called"))
 	}
 
+	// 拦截器在注册之后才设置作用范围，因此在这里统一检查
+	ctx.checkAdvisors()
+
+	// 执行 Bean 工厂函数，生成的 Bean 需要在决议之前注册
+	ctx.runBeanFactories()
+
+	// 使用替换列表替换已注册的 Bean
+	ctx.replaceBeans()
+
 	// 处理 Method Bean 等
 	ctx.registerAllBeans()
 
@@ -636,10 +808,15 @@ func (ctx *applicationContext) Close(beforeDestroy ...func()) {
 
 	// 按照顺序执行销毁函数
 	for i := ctx.destroyers.Front(); i != nil; i = i.Next() {
-		d := i.Value.(*destroyer)
-		if err := d.bean.GetDestroy().Run(assembly); err != nil {
-			log.Error(err)
-		}
+		ctx.runDestroy(assembly, i.Value.(*destroyer))
+	}
+}
+
+// runDestroy 执行 Bean 的销毁函数，销毁函数 panic 时也能结束跟踪
+func (ctx *applicationContext) runDestroy(assembly *defaultBeanAssembly, d *destroyer) {
+	defer ctx.tracer.begin(TracePhase_Destroy, d.bean.BeanId()).end()
+	if err := d.bean.GetDestroy().Run(assembly); err != nil {
+		log.Error(err)
 	}
 }
 
@@ -17,11 +17,13 @@
 package core_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"image"
+	"io"
 	"reflect"
 	"sort"
 	"strconv"
@@ -31,6 +33,8 @@ import (
 	"time"
 
 	"github.com/go-spring/spring-core/bean"
+	"github.com/go-spring/spring-core/cond"
+	"github.com/go-spring/spring-core/conf"
 	"github.com/go-spring/spring-core/core"
 	pkg1 "github.com/go-spring/spring-core/core/testdata/pkg/bar"
 	pkg2 "github.com/go-spring/spring-core/core/testdata/pkg/foo"
@@ -3059,3 +3063,396 @@ func TestApplicationContext_NameEquivalence(t *testing.T) {
 		ctx.AutoWireBeans()
 	}, `duplicate registration, bean: `)
 }
+
+type DBInstanceConfig struct {
+	Url     string `value:"${url}"`
+	MaxConn int    `value:"${max-conn:=10}"`
+}
+
+type DBInstance struct {
+	Config *DBInstanceConfig
+}
+
+type DBInstanceHolder struct {
+	Instances []*DBInstance `autowire:"[]"`
+	Master    *DBInstance   `autowire:"master"`
+}
+
+func TestApplicationContext_RegisterBeanFactory(t *testing.T) {
+
+	t.Run("bind struct", func(t *testing.T) {
+		ctx := core.NewApplicationContext()
+		ctx.Property("db.instances.master.url", "mysql://master")
+		ctx.Property("db.instances.slave.url", "mysql://slave")
+		ctx.Property("db.instances.slave.max-conn", "20")
+
+		ctx.RegisterBeanFactory(func(m map[string]DBInstanceConfig) ([]*bean.BeanDefinition, error) {
+			var result []*bean.BeanDefinition
+			for name, c := range m {
+				c := c
+				result = append(result, bean.Ref(&DBInstance{Config: &c}).WithName(name))
+			}
+			return result, nil
+		}, "${db.instances}")
+
+		holder := new(DBInstanceHolder)
+		ctx.RegisterBean(bean.Ref(holder))
+		ctx.AutoWireBeans()
+
+		util.AssertEqual(t, len(holder.Instances), 2)
+		util.AssertEqual(t, *holder.Master.Config, DBInstanceConfig{Url: "mysql://master", MaxConn: 10})
+
+		var slave *DBInstance
+		ok := ctx.GetBean(&slave, "slave")
+		util.AssertEqual(t, ok, true)
+		util.AssertEqual(t, *slave.Config, DBInstanceConfig{Url: "mysql://slave", MaxConn: 20})
+	})
+
+	t.Run("properties", func(t *testing.T) {
+		ctx := core.NewApplicationContext()
+		ctx.Property("names", "a,b,c")
+
+		ctx.RegisterBeanFactory(func(p conf.Properties) []*bean.BeanDefinition {
+			var result []*bean.BeanDefinition
+			for _, name := range strings.Split(cast.ToString(p.Get("names")), ",") {
+				result = append(result, bean.Ref(&DBInstance{}).WithName(name))
+			}
+			return result
+		})
+
+		ctx.AutoWireBeans()
+
+		var instances []*DBInstance
+		ctx.CollectBeans(&instances)
+		util.AssertEqual(t, len(instances), 3)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		util.AssertPanic(t, func() {
+			ctx := core.NewApplicationContext()
+			ctx.RegisterBeanFactory(func(p conf.Properties) ([]*bean.BeanDefinition, error) {
+				return nil, errors.New("no instance")
+			})
+			ctx.AutoWireBeans()
+		}, "return error: no instance")
+	})
+
+	t.Run("nil bean", func(t *testing.T) {
+		util.AssertPanic(t, func() {
+			ctx := core.NewApplicationContext()
+			ctx.RegisterBeanFactory(func(p conf.Properties) []*bean.BeanDefinition {
+				return []*bean.BeanDefinition{bean.Ref(new(int)), nil}
+			})
+			ctx.AutoWireBeans()
+		}, "bean factory: .* return nil bean definition at 1")
+	})
+
+	t.Run("invalid func", func(t *testing.T) {
+		util.AssertPanic(t, func() {
+			ctx := core.NewApplicationContext()
+			ctx.RegisterBeanFactory(func(p conf.Properties) *bean.BeanDefinition { return nil })
+		}, "bean factory must be")
+		util.AssertPanic(t, func() {
+			ctx := core.NewApplicationContext()
+			ctx.RegisterBeanFactory(func(m map[string]string) []*bean.BeanDefinition { return nil })
+		}, "bean factory should have a property tag")
+	})
+}
+
+type Greeter interface {
+	Greet(name string) (string, error)
+}
+
+type greeter struct{ prefix string }
+
+func (g *greeter) Greet(name string) (string, error) {
+	if name == "" {
+		return "", errors.New("empty name")
+	}
+	return g.prefix + name, nil
+}
+
+type greeterProxy struct {
+	h bean.InvocationHandler
+}
+
+func (p *greeterProxy) Greet(name string) (string, error) {
+	out := p.h.Invoke("Greet", name)
+	err, _ := out[1].(error)
+	return out[0].(string), err
+}
+
+func init() {
+	bean.RegisterProxyFactory(func(h bean.InvocationHandler) Greeter {
+		return &greeterProxy{h}
+	})
+}
+
+type GreeterHolder struct {
+	Greeter  Greeter   `autowire:""`
+	Greeters []Greeter `autowire:"[]"`
+	Impl     *greeter  `autowire:""`
+}
+
+func TestApplicationContext_Intercept(t *testing.T) {
+
+	t.Run("order", func(t *testing.T) {
+		var calls []string
+
+		record := func(name string) bean.InterceptorFunc {
+			return func(inv *bean.Invocation) []reflect.Value {
+				calls = append(calls, name+":"+inv.Method)
+				return inv.Proceed()
+			}
+		}
+
+		ctx := core.NewApplicationContext()
+		ctx.RegisterBean(bean.Ref(&greeter{"hello "}).Export((*Greeter)(nil)))
+		ctx.Intercept(record("second")).On((*greeter)(nil)).Order(2)
+		ctx.Intercept(record("first")).OnType(func(t reflect.Type) bool {
+			return t == reflect.TypeOf((*Greeter)(nil)).Elem()
+		}).Order(1)
+
+		holder := new(GreeterHolder)
+		ctx.RegisterBean(bean.Ref(holder))
+		ctx.AutoWireBeans()
+
+		_, ok := holder.Greeter.(*greeterProxy)
+		util.AssertEqual(t, ok, true)
+		util.AssertEqual(t, holder.Greeters[0], holder.Greeter)
+
+		s, err := holder.Greeter.Greet("go")
+		util.AssertEqual(t, s, "hello go")
+		util.AssertEqual(t, err, nil)
+		util.AssertEqual(t, calls, []string{"first:Greet", "second:Greet"})
+
+		_, err = holder.Greeter.Greet("")
+		util.AssertEqual(t, err, errors.New("empty name"))
+
+		// 按照具体类型注入时不会被拦截
+		s, _ = holder.Impl.Greet("go")
+		util.AssertEqual(t, s, "hello go")
+		util.AssertEqual(t, len(calls), 4)
+	})
+
+	t.Run("short circuit", func(t *testing.T) {
+		ctx := core.NewApplicationContext()
+		ctx.RegisterBean(bean.Ref(&greeter{"hello "}).Export((*Greeter)(nil)))
+		ctx.Intercept(bean.InterceptorFunc(func(inv *bean.Invocation) []reflect.Value {
+			return []reflect.Value{reflect.ValueOf("mock"), reflect.Zero(reflect.TypeOf((*error)(nil)).Elem())}
+		})).On("*core_test.greeter")
+		ctx.AutoWireBeans()
+
+		var g Greeter
+		ctx.GetBean(&g)
+		s, err := g.Greet("go")
+		util.AssertEqual(t, s, "mock")
+		util.AssertEqual(t, err, nil)
+	})
+
+	t.Run("no target", func(t *testing.T) {
+		util.AssertPanic(t, func() {
+			ctx := core.NewApplicationContext()
+			ctx.Intercept(bean.InterceptorFunc(func(inv *bean.Invocation) []reflect.Value {
+				return inv.Proceed()
+			}))
+			ctx.AutoWireBeans()
+		}, "interceptor should be set On or OnType")
+	})
+
+	t.Run("no proxy factory", func(t *testing.T) {
+		util.AssertPanic(t, func() {
+			ctx := core.NewApplicationContext()
+			ctx.RegisterBean(bean.Ref(bytes.NewBuffer(nil)).Export((*io.Writer)(nil)))
+			ctx.Intercept(bean.InterceptorFunc(func(inv *bean.Invocation) []reflect.Value {
+				return inv.Proceed()
+			})).OnType(func(t reflect.Type) bool { return true })
+			ctx.AutoWireBeans()
+			var w io.Writer
+			ctx.GetBean(&w)
+		}, "no proxy factory for interface io.Writer")
+	})
+}
+
+type RefreshableConfig struct {
+	Port    int           `value:"${server.port}" refresh:"true"`
+	Timeout time.Duration `value:"${server.timeout:=1s}" refresh:"true"`
+	Name    string        `value:"${server.name}"`
+}
+
+func TestApplicationContext_RefreshProperties(t *testing.T) {
+
+	ctx := core.NewApplicationContext()
+	ctx.Property("server.port", 8080)
+	ctx.Property("server.name", "a")
+
+	c := new(RefreshableConfig)
+	ctx.RegisterBean(bean.Ref(c))
+	ctx.AutoWireBeans()
+
+	var watched []string
+	ctx.Properties().Watch("server", func(key string, oldValue, newValue interface{}) {
+		// 监听者能够看到刷新后的字段
+		watched = append(watched, fmt.Sprintf("%s=%v,%d", key, newValue, c.Port))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		err := ctx.RefreshProperties(map[string]interface{}{
+			"server.port":    "9090",
+			"server.timeout": "3s",
+			"server.name":    "b",
+		})
+		util.AssertEqual(t, err, nil)
+		util.AssertEqual(t, c.Port, 9090)
+		util.AssertEqual(t, c.Timeout, 3*time.Second)
+		util.AssertEqual(t, c.Name, "a") // 没有标记 refresh 的字段不刷新
+		util.AssertEqual(t, ctx.GetProperty("server.name"), "b")
+		util.AssertEqual(t, watched, []string{
+			"server.name=b,9090",
+			"server.port=9090,9090",
+			"server.timeout=3s,9090",
+		})
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		watched = nil
+		err := ctx.RefreshProperties(map[string]interface{}{
+			"server.port":    "abc",
+			"server.timeout": "5s",
+		})
+		util.AssertEqual(t, err, errors.New("property value server.port isn't int type"))
+		util.AssertEqual(t, c.Port, 9090)
+		util.AssertEqual(t, c.Timeout, 3*time.Second)
+		util.AssertEqual(t, ctx.GetProperty("server.timeout"), "3s")
+		util.AssertEqual(t, len(watched), 0)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		err := ctx.RefreshProperties(map[string]interface{}{"server.timeout": nil})
+		util.AssertEqual(t, err, nil)
+		util.AssertEqual(t, c.Timeout, time.Second)
+		err = ctx.RefreshProperties(map[string]interface{}{"server.port": nil})
+		util.AssertEqual(t, err, errors.New(`RefreshableConfig.$Port properties "server.port" not config`))
+	})
+}
+
+type MetadataServer struct {
+	Port    int           `value:"${server.port:=8080}"`
+	Timeout time.Duration `value:"${server.timeout:=3s}"`
+	DB      struct {
+		URL string `value:"${url}"`
+	} `value:"${db}"`
+}
+
+func NewMetadataClient(host string) *MetadataServer {
+	return &MetadataServer{}
+}
+
+func TestApplicationContext_Metadata(t *testing.T) {
+
+	ctx := core.NewApplicationContext()
+	ctx.Property("db.url", "mysql://")
+	ctx.Property("client.host", "localhost")
+	ctx.RegisterBean(bean.Ref(new(MetadataServer)).WithName("server"))
+	ctx.RegisterBean(bean.Make(NewMetadataClient, "${client.host}").WithName("client"))
+	ctx.RegisterBean(bean.Ref(new(int)).WithCondition(cond.PropertyCondition("feature.enabled")))
+	ctx.AutoWireBeans()
+
+	var name string
+	util.AssertEqual(t, ctx.BindProperty("app.name", &name) != nil, true)
+
+	server := "github.com/go-spring/spring-core/core_test/core_test.MetadataServer:server"
+	client := "github.com/go-spring/spring-core/core_test/core_test.MetadataServer:client"
+
+	m := ctx.Metadata()
+	util.AssertEqual(t, m.All(), []conf.PropertyMeta{
+		{Key: "app.name", Type: "string"},
+		{Key: "client.host", Type: "string", Owners: []string{client}},
+		{Key: "db.url", Type: "string", Owners: []string{client, server}},
+		{Key: "feature.enabled"},
+		{Key: "server.port", Type: "int", Default: "8080", Owners: []string{client, server}},
+		{Key: "server.timeout", Type: "time.Duration", Default: "3s", Owners: []string{client, server}},
+	})
+	util.AssertEqual(t, m.Known("server.max-conn"), false)
+	util.AssertEqual(t, m.Known("db.url"), true)
+}
+
+type DataSourceConfig struct {
+	sync.Mutex
+	URL          string
+	MaxIdleConns int
+	Timeout      time.Duration
+	Hosts        []string
+	Pool         struct {
+		Size int
+	}
+	Version string `value:"${version:=1.0}"`
+	name    string // 私有字段不绑定
+}
+
+func TestApplicationContext_ConfigurationProperties(t *testing.T) {
+
+	ctx := core.NewApplicationContext()
+	ctx.Property("db.url", "mysql://localhost")
+	ctx.Property("db.max_idle_conns", 10)
+	ctx.Property("db.hosts[0]", "a")
+	ctx.Property("db.hosts[1]", "b")
+	ctx.Property("db.pool.size", 5)
+	ctx.Property("db.unknown", "x")
+
+	c := &DataSourceConfig{Timeout: time.Second, name: "db"}
+	ctx.RegisterBean(bean.Ref(c).ConfigurationProperties("db").Refreshable())
+	fixed := &DataSourceConfig{}
+	ctx.RegisterBean(bean.Ref(fixed).WithName("fixed").ConfigurationProperties("db"))
+	ctx.AutoWireBeans()
+
+	util.AssertEqual(t, c.URL, "mysql://localhost")
+	util.AssertEqual(t, c.MaxIdleConns, 10)
+	util.AssertEqual(t, c.Timeout, time.Second) // 没有配置的字段保持原来的值
+	util.AssertEqual(t, c.Hosts, []string{"a", "b"})
+	util.AssertEqual(t, c.Pool.Size, 5)
+	util.AssertEqual(t, c.Version, "1.0")
+	util.AssertEqual(t, c.name, "db")
+
+	meta, ok := ctx.Metadata().Get("db.max-idle-conns")
+	util.AssertEqual(t, ok, true)
+	util.AssertEqual(t, meta.Type, "int")
+	_, ok = ctx.Metadata().Get("db.timeout")
+	util.AssertEqual(t, ok, true)
+
+	err := ctx.RefreshProperties(map[string]interface{}{
+		"db.max-idle-conns": 20,
+		"db.timeout":        "3s",
+	})
+	util.AssertEqual(t, err, nil)
+	util.AssertEqual(t, c.MaxIdleConns, 20)
+	util.AssertEqual(t, c.Timeout, 3*time.Second)
+	util.AssertEqual(t, c.name, "db")
+	util.AssertEqual(t, fixed.MaxIdleConns, 10) // 没有设置可刷新的 Bean 不会重新绑定
+
+	// 只重新绑定导出字段，私有字段和锁可以被 Bean 自己的协程并发访问
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			c.Lock()
+			c.name = fmt.Sprintf("db-%d", i)
+			c.Unlock()
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		err = ctx.RefreshProperties(map[string]interface{}{"db.url": fmt.Sprintf("mysql://host-%d", i)})
+		util.AssertEqual(t, err, nil)
+	}
+	<-done
+	util.AssertEqual(t, c.URL, "mysql://host-9")
+	util.AssertEqual(t, c.name, "db-99")
+
+	err = ctx.RefreshProperties(map[string]interface{}{"db.pool.size": "abc"})
+	util.AssertEqual(t, err, errors.New("property value db.pool.size isn't int type"))
+	util.AssertEqual(t, c.Pool.Size, 5)
+
+	util.AssertPanic(t, func() {
+		bean.Ref(new(int)).ConfigurationProperties("db")
+	}, "configuration properties bean should be \\*struct")
+}
@@ -36,10 +36,10 @@ type GoFunc func()
 // 的 Bean 了，这样做是因为实现起来更简单而且性能更高。
 type ApplicationContext interface {
 
-	// LoadProperties 加载属性配置，支持 properties、yaml 和 toml 三种文件格式。
+	// LoadProperties 加载属性配置，按照扩展名选择配置类型，支持 properties、yaml、toml、json、ini、env 等格式。
 	LoadProperties(filename string) error
 
-	// ReadProperties 读取属性配置，支持 properties、yaml 和 toml 三种文件格式。
+	// ReadProperties 读取属性配置，configType 为空时根据内容推测配置类型，参见 conf.RegisterReader。
 	ReadProperties(reader io.Reader, configType string) error
 
 	// BindProperty 根据类型获取属性值，属性名称统一转成小写。
@@ -60,18 +60,56 @@ type ApplicationContext interface {
 	// Properties 获取 Properties 对象
 	Properties() conf.Properties
 
+	// Metadata 返回属性的元数据，用于生成命令行帮助信息和配置文档
+	Metadata() *conf.Metadata
+
+	// CollectMetadata 根据已经注册的 Bean 定义收集属性的元数据，包括 value 标签以及
+	// ConfigurationProperties 前缀下的字段，不需要进行注入，因此可以在解析命令行参数
+	// 之前调用。Bean 工厂生成的 Bean 以及条件使用的属性在 AutoWireBeans 时才会记录。
+	CollectMetadata()
+
+	// RefreshProperties 更新属性值并重新绑定标记了 refresh:"true" 的字段，value 为 nil
+	// 表示删除属性。所有字段重新绑定成功后才会生效，否则整个更新被拒绝并返回错误。
+	// 要么全部生效要么全部拒绝只对绑定过程成立，字段是直接赋值的，调用者所在的协程
+	// (例如配置监视器) 写入字段时不会持有 Bean 的锁，其他协程并发读取可刷新的字段会
+	// 产生数据竞争。需要并发读取时应该在 Properties().Watch 的通知中加锁复制一份，
+	// 通知在字段刷新之后发出。
+	RefreshProperties(changes map[string]interface{}) error
+
 	// Context 返回上下文接口
 	Context() context.Context
 
-	// GetProfile 返回运行环境
+	// GetProfile 返回运行环境，多个运行环境之间使用逗号分隔
 	GetProfile() string
 
-	// Profile 设置运行环境
+	// GetProfiles 返回按照声明顺序排列的所有运行环境
+	GetProfiles() []string
+
+	// Profile 设置运行环境，多个运行环境之间使用逗号分隔，后面的优先级更高
 	Profile(profile string)
 
 	// RegisterBean 注册 bean.BeanDefinition 对象。
 	RegisterBean(bd *bean.BeanDefinition) *bean.BeanDefinition
 
+	// ReplaceBean 使用 bd 替换 selector 选中的 Bean，常用于测试时使用模拟对象替换真实的 Bean。
+	// bd 会继承被替换 Bean 的名称、导出接口和主版本标记，替换在 AutoWireBeans 开始时进行。
+	ReplaceBean(selector bean.BeanSelector, bd *bean.BeanDefinition)
+
+	// RegisterBeanFactory 注册一个根据属性配置批量生成 Bean 定义的工厂函数，函数只有一个
+	// 入参，返回 []*bean.BeanDefinition 或者 ([]*bean.BeanDefinition, error)。入参是
+	// conf.Properties 时传入所有属性，否则通过 tag 进行属性绑定，例如 "${db.instances}"。
+	// 工厂函数在 AutoWireBeans 开始时、Bean 决议之前执行，生成的 Bean 和直接注册的 Bean
+	// 一样参与决议和注入，生成的 Bean 定义不能为 nil。
+	RegisterBeanFactory(fn interface{}, tag ...string)
+
+	// Intercept 注册一个方法拦截器，拦截器只作用于按照接口类型注入的 Bean，并且
+	// 该接口类型需要通过 bean.RegisterProxyFactory 注册代理工厂。
+	Intercept(interceptor bean.Interceptor) *Advisor
+
+	// SetTracer 设置生命周期跟踪器，记录每个 Bean 决议、注入、初始化和销毁的时间
+	// 以及触发者，需要在 AutoWireBeans 之前调用，默认不跟踪。
+	SetTracer(tracer *Tracer)
+
 	// AutoWireBeans 对所有 Bean 进行依赖注入和属性绑定
 	AutoWireBeans()
 
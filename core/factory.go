@@ -0,0 +1,115 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/go-spring/spring-core/bean"
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/util"
+)
+
+var (
+	// propertiesType conf.Properties 的反射类型
+	propertiesType = reflect.TypeOf((*conf.Properties)(nil)).Elem()
+
+	// beanDefinitionsType []*bean.BeanDefinition 的反射类型
+	beanDefinitionsType = reflect.TypeOf([]*bean.BeanDefinition{})
+
+	// errorType error 的反射类型
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// beanFactory 根据属性配置批量生成 Bean 定义的工厂函数，在 Bean 决议之前执行。
+type beanFactory struct {
+	fn  interface{}
+	tag string // 入参的属性绑定，入参是 conf.Properties 时为空
+}
+
+// validBeanFactoryFunc 返回是否是合法的工厂函数，合法的工厂函数只有一个入参，
+// 第一个返回值必须是 []*bean.BeanDefinition，如果有第二个返回值必须是 error。
+func validBeanFactoryFunc(fnType reflect.Type) bool {
+
+	if fnType.Kind() != reflect.Func || fnType.NumIn() != 1 {
+		return false
+	}
+
+	if fnType.NumOut() < 1 || fnType.NumOut() > 2 {
+		return false
+	}
+
+	if fnType.Out(0) != beanDefinitionsType {
+		return false
+	}
+
+	return fnType.NumOut() == 1 || fnType.Out(1) == errorType
+}
+
+// newBeanFactory beanFactory 的构造函数
+func newBeanFactory(fn interface{}, tag []string) *beanFactory {
+
+	fnType := reflect.TypeOf(fn)
+	if !validBeanFactoryFunc(fnType) {
+		t1 := "func(T)[]*bean.BeanDefinition"
+		t2 := "func(T)([]*bean.BeanDefinition, error)"
+		panic(fmt.Errorf("bean factory must be %s or %s", t1, t2))
+	}
+
+	f := &beanFactory{fn: fn}
+
+	if fnType.In(0) != propertiesType {
+		if len(tag) == 0 || tag[0] == "" {
+			panic(errors.New("bean factory should have a property tag"))
+		}
+		f.tag = tag[0]
+	}
+
+	return f
+}
+
+// create 执行工厂函数，返回生成的 Bean 定义列表
+func (f *beanFactory) create(p conf.Properties, m *conf.Metadata) ([]*bean.BeanDefinition, error) {
+
+	fnType := reflect.TypeOf(f.fn)
+	fnValue := reflect.ValueOf(f.fn)
+
+	var arg reflect.Value
+
+	if f.tag == "" {
+		arg = reflect.ValueOf(p)
+	} else {
+		arg = reflect.New(fnType.In(0)).Elem()
+		_, _, fnName := util.FileLine(f.fn)
+		opt := conf.BindOption{FieldName: fnName, Metadata: m}
+		if err := conf.BindStructField(p, arg, f.tag, opt); err != nil {
+			return nil, err
+		}
+	}
+
+	out := fnValue.Call([]reflect.Value{arg})
+
+	if len(out) == 2 {
+		if err := out[1].Interface(); err != nil {
+			return nil, err.(error)
+		}
+	}
+
+	return out[0].Interface().([]*bean.BeanDefinition), nil
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/go-spring/spring-core/conf"
+	"github.com/go-spring/spring-core/log"
+)
+
+// refreshable 标记了 refresh:"true" 的属性绑定字段，或者设置了属性前缀并且可刷新
+// 的 Bean，属性值变化时重新绑定。
+type refreshable struct {
+	v      reflect.Value
+	tag    string
+	prefix string // 不为空时按照前缀重新绑定结构体的导出字段，参见 conf.PrefixFields
+	opt    conf.BindOption
+}
+
+// addRefreshable 添加一个可刷新的属性绑定字段
+func (ctx *applicationContext) addRefreshable(v reflect.Value, tag string, opt conf.BindOption) {
+	ctx.refreshMutex.Lock()
+	defer ctx.refreshMutex.Unlock()
+	ctx.refreshables = append(ctx.refreshables, &refreshable{v: v, tag: tag, opt: opt})
+}
+
+// addRefreshablePrefix 添加一个按照前缀绑定属性值的 Bean，v 是 Bean 指向的结构体。
+func (ctx *applicationContext) addRefreshablePrefix(v reflect.Value, prefix string, opt conf.BindOption) {
+	ctx.refreshMutex.Lock()
+	defer ctx.refreshMutex.Unlock()
+	ctx.refreshables = append(ctx.refreshables, &refreshable{v: v, prefix: prefix, opt: opt})
+}
+
+// RefreshProperties 更新属性值并重新绑定可刷新的字段，value 为 nil 表示删除属性。
+// 所有字段先绑定到临时变量上进行类型检查，任何一个字段绑定失败都会拒绝整个更新。
+// 字段的写入没有同步，并发读取可刷新字段的要求参见 ApplicationContext.RefreshProperties。
+func (ctx *applicationContext) RefreshProperties(changes map[string]interface{}) error {
+
+	ctx.refreshMutex.Lock()
+	defer ctx.refreshMutex.Unlock()
+
+	// 在属性值的副本上应用变化，此时不会通知监听者
+	p := conf.New()
+	p.SetEnv(ctx.properties.Env())
+	ctx.properties.Range(func(k string, v interface{}) {
+		p.Set(k, v)
+		p.SetOrigin(k, ctx.properties.Origin(k))
+	})
+	for _, fn := range ctx.properties.Converters() {
+		if err := p.Convert(fn); err != nil {
+			return err
+		}
+	}
+	for k, v := range changes {
+		p.Set(k, v)
+		p.SetOrigin(k, ctx.properties.Origin(k)) // 调用者可以提前记录变化的属性的来源
+	}
+
+	// 将所有可刷新字段绑定到临时变量上，按照前缀绑定的 Bean 只重新绑定配置了的导出
+	// 字段，没有配置的字段、私有字段以及其他方式注入的字段保持原来的值。
+	var fields, values []reflect.Value
+	bind := func(field reflect.Value, tag string, opt conf.BindOption) error {
+		v := reflect.New(field.Type()).Elem()
+		if err := conf.BindStructField(p, v, tag, opt); err != nil {
+			return err
+		}
+		fields = append(fields, field)
+		values = append(values, v)
+		return nil
+	}
+	for _, r := range ctx.refreshables {
+		if r.prefix == "" {
+			if err := bind(r.v, r.tag, r.opt); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, f := range conf.PrefixFields(p, r.v, r.prefix, r.opt) {
+			if f.Missing {
+				continue
+			}
+			if err := bind(f.Value, "${"+f.Key+"}", f.Opt); err != nil {
+				return err
+			}
+		}
+	}
+
+	// 全部绑定成功后再更新字段的值
+	for i, field := range fields {
+		field.Set(values[i])
+	}
+
+	keys := make([]string, 0, len(changes))
+	for k := range changes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// 最后更新属性值，这时监听者能够看到刷新后的字段
+	for _, k := range keys {
+		log.Tracef("refresh %s=%v", k, conf.MaskValue(ctx.properties, k, changes[k]))
+		ctx.properties.Set(k, changes[k])
+	}
+	return nil
+}
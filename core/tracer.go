@@ -0,0 +1,162 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TracePhase Bean 生命周期的阶段
+type TracePhase string
+
+const (
+	TracePhase_Resolve = TracePhase("resolve") // 决议
+	TracePhase_Wire    = TracePhase("wire")    // 注入，包含依赖项的注入时间
+	TracePhase_Init    = TracePhase("init")    // 执行初始化函数
+	TracePhase_Destroy = TracePhase("destroy") // 执行销毁函数
+)
+
+// TraceEvent Bean 生命周期中的一个事件
+type TraceEvent struct {
+	Bean     string        `json:"bean"`             // Bean 的 ID
+	Phase    TracePhase    `json:"phase"`            // 所处阶段
+	Parent   string        `json:"parent,omitempty"` // 触发该事件的 Bean 的 ID
+	Start    time.Time     `json:"start"`            // 开始时间
+	Duration time.Duration `json:"duration"`         // 持续时间，单位纳秒
+}
+
+// Tracer 记录 Bean 的决议、注入、初始化和销毁过程，用于分析启动缓慢等问题。
+// Tracer 是可选的，通过 ApplicationContext.SetTracer 开启，为 nil 时不做任何记录。
+type Tracer struct {
+	mutex  sync.Mutex
+	start  time.Time
+	events []TraceEvent
+	stacks map[TracePhase][]string // 每个阶段正在进行中的 Bean
+}
+
+// NewTracer Tracer 的构造函数
+func NewTracer() *Tracer {
+	return &Tracer{
+		start:  time.Now(),
+		stacks: make(map[TracePhase][]string),
+	}
+}
+
+// traceSpan 一个正在进行中的事件
+type traceSpan struct {
+	tracer *Tracer
+	event  TraceEvent
+}
+
+// begin 开始记录一个事件，同一阶段中尚未结束的最近一个 Bean 就是它的触发者。
+func (t *Tracer) begin(phase TracePhase, beanId string) *traceSpan {
+	if t == nil {
+		return nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	e := TraceEvent{Bean: beanId, Phase: phase, Start: time.Now()}
+	if s := t.stacks[phase]; len(s) > 0 {
+		e.Parent = s[len(s)-1]
+	}
+	t.stacks[phase] = append(t.stacks[phase], beanId)
+	return &traceSpan{tracer: t, event: e}
+}
+
+// end 结束记录一个事件
+func (s *traceSpan) end() {
+	if s == nil {
+		return
+	}
+
+	t := s.tracer
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	s.event.Duration = time.Since(s.event.Start)
+	t.events = append(t.events, s.event)
+
+	stack := t.stacks[s.event.Phase]
+	t.stacks[s.event.Phase] = stack[:len(stack)-1]
+}
+
+// Events 返回按照开始时间排序的事件列表
+func (t *Tracer) Events() []TraceEvent {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	events := make([]TraceEvent, len(t.events))
+	copy(events, t.events)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Start.Before(events[j].Start)
+	})
+	return events
+}
+
+// WriteJSON 以 JSON 数组的格式输出事件列表
+func (t *Tracer) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(t.Events())
+}
+
+// chromeTraceEvent Chrome Trace Event Format 中的 Complete 事件
+type chromeTraceEvent struct {
+	Name string            `json:"name"`
+	Cat  string            `json:"cat"`
+	Ph   string            `json:"ph"`
+	Ts   int64             `json:"ts"`  // 开始时间，单位微秒
+	Dur  int64             `json:"dur"` // 持续时间，单位微秒
+	Pid  int               `json:"pid"`
+	Tid  int               `json:"tid"`
+	Args map[string]string `json:"args,omitempty"`
+}
+
+// WriteChromeTrace 以 Chrome Trace Event Format 的格式输出事件列表，输出
+// 结果可以通过 chrome://tracing 或者 https://ui.perfetto.dev 打开。
+func (t *Tracer) WriteChromeTrace(w io.Writer) error {
+
+	var trace struct {
+		TraceEvents     []chromeTraceEvent `json:"traceEvents"`
+		DisplayTimeUnit string             `json:"displayTimeUnit"`
+	}
+
+	trace.DisplayTimeUnit = "ms"
+	trace.TraceEvents = make([]chromeTraceEvent, 0)
+
+	for _, e := range t.Events() {
+		ce := chromeTraceEvent{
+			Name: e.Bean,
+			Cat:  string(e.Phase),
+			Ph:   "X",
+			Ts:   int64(e.Start.Sub(t.start) / time.Microsecond),
+			Dur:  int64(e.Duration / time.Microsecond),
+			Pid:  1,
+			Tid:  1,
+		}
+		if e.Parent != "" {
+			ce.Args = map[string]string{"parent": e.Parent}
+		}
+		trace.TraceEvents = append(trace.TraceEvents, ce)
+	}
+
+	return json.NewEncoder(w).Encode(&trace)
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-spring/spring-core/bean"
+	"github.com/go-spring/spring-core/core"
+	"github.com/go-spring/spring-core/util"
+)
+
+type TracedRepository struct{}
+
+type TracedService struct {
+	Repository *TracedRepository `autowire:""`
+}
+
+func (s *TracedService) init() {
+	time.Sleep(time.Millisecond)
+}
+
+func (s *TracedService) destroy() {}
+
+func TestTracer(t *testing.T) {
+
+	tracer := core.NewTracer()
+
+	ctx := core.NewApplicationContext()
+	ctx.SetTracer(tracer)
+	ctx.RegisterBean(bean.Ref(new(TracedService)).
+		Init((*TracedService).init).
+		Destroy((*TracedService).destroy))
+	ctx.RegisterBean(bean.Ref(new(TracedRepository)))
+	ctx.AutoWireBeans()
+	ctx.Close()
+
+	const (
+		service    = "github.com/go-spring/spring-core/core_test/core_test.TracedService:*core_test.TracedService"
+		repository = "github.com/go-spring/spring-core/core_test/core_test.TracedRepository:*core_test.TracedRepository"
+	)
+
+	find := func(phase core.TracePhase, beanId string) core.TraceEvent {
+		for _, e := range tracer.Events() {
+			if e.Phase == phase && e.Bean == beanId {
+				return e
+			}
+		}
+		t.Fatalf("can't find %s event of %s", phase, beanId)
+		return core.TraceEvent{}
+	}
+
+	find(core.TracePhase_Resolve, service)
+	find(core.TracePhase_Resolve, repository)
+	find(core.TracePhase_Destroy, service)
+
+	// Repository 的注入可能由 Service 触发，也可能先于 Service 完成
+	if e := find(core.TracePhase_Wire, repository); e.Parent != "" {
+		util.AssertEqual(t, e.Parent, service)
+	}
+
+	wire := find(core.TracePhase_Wire, service)
+	init := find(core.TracePhase_Init, service)
+	util.AssertEqual(t, init.Duration >= time.Millisecond, true)
+	util.AssertEqual(t, wire.Duration >= init.Duration, true)
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		util.AssertEqual(t, tracer.WriteJSON(&buf), nil)
+		var events []map[string]interface{}
+		util.AssertEqual(t, json.Unmarshal(buf.Bytes(), &events), nil)
+		util.AssertEqual(t, len(events), len(tracer.Events()))
+	})
+
+	t.Run("chrome", func(t *testing.T) {
+		var buf bytes.Buffer
+		util.AssertEqual(t, tracer.WriteChromeTrace(&buf), nil)
+		var trace struct {
+			TraceEvents []struct {
+				Name string `json:"name"`
+				Cat  string `json:"cat"`
+				Ph   string `json:"ph"`
+				Dur  int64  `json:"dur"`
+			} `json:"traceEvents"`
+		}
+		util.AssertEqual(t, json.Unmarshal(buf.Bytes(), &trace), nil)
+		util.AssertEqual(t, len(trace.TraceEvents), len(tracer.Events()))
+		for _, e := range trace.TraceEvents {
+			util.AssertEqual(t, e.Ph, "X")
+			if e.Cat == "init" {
+				util.AssertEqual(t, e.Dur >= 1000, true)
+			}
+		}
+	})
+}
+
+type FailedService struct{}
+
+func (s *FailedService) init() error {
+	return errors.New("init error")
+}
+
+func TestTracer_InitPanic(t *testing.T) {
+
+	tracer := core.NewTracer()
+
+	ctx := core.NewApplicationContext()
+	ctx.SetTracer(tracer)
+	ctx.RegisterBean(bean.Ref(new(FailedService)).Init((*FailedService).init))
+	util.AssertPanic(t, func() { ctx.AutoWireBeans() }, "init error")
+
+	const service = "github.com/go-spring/spring-core/core_test/core_test.FailedService:*core_test.FailedService"
+
+	var phases []core.TracePhase
+	for _, e := range tracer.Events() {
+		if e.Bean == service {
+			phases = append(phases, e.Phase)
+		}
+	}
+	util.AssertEqual(t, phases, []core.TracePhase{
+		core.TracePhase_Resolve,
+		core.TracePhase_Wire,
+		core.TracePhase_Init,
+	})
+}
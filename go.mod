@@ -6,4 +6,5 @@ require (
 	github.com/magiconair/properties v1.8.1
 	github.com/spf13/cast v1.3.1
 	github.com/spf13/viper v1.6.3
+	gopkg.in/yaml.v2 v2.2.4
 )
@@ -16,6 +16,15 @@
 
 package util
 
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
 // Validator 参数校验器接口
 type Validator interface {
 	Validate(i interface{}) error
@@ -23,11 +32,20 @@ type Validator interface {
 
 var validator Validator
 
+// DefaultValidator 内置的参数校验器，按照结构体字段的 validate 标签进行校验。没有
+// 设置参数校验器时属性绑定使用它校验字段，需要全局使用时通过 SetValidator 设置。
+var DefaultValidator Validator = &defaultValidator{}
+
 // SetValidator 设置参数校验器
 func SetValidator(v Validator) {
 	validator = v
 }
 
+// GetValidator 返回设置的参数校验器，没有设置时返回 nil。
+func GetValidator() Validator {
+	return validator
+}
+
 // Validate 参数校验
 func Validate(i interface{}) error {
 	if validator != nil {
@@ -35,3 +53,179 @@ func Validate(i interface{}) error {
 	}
 	return nil
 }
+
+// FieldError 结构体字段的校验错误
+type FieldError struct {
+	Field string // 字段的名称
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
+}
+
+// FieldErrors 结构体的多个字段的校验错误
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	s := make([]string, len(e))
+	for i, err := range e {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+// defaultValidator 默认的参数校验器，校验结构体或者结构体指针的每个字段，不会递归
+// 校验嵌套的结构体。validate 标签的规则之间使用逗号分隔，支持以下规则:
+//
+//	required    不能是零值
+//	min=n       数值不能小于 n，字符串、数组和 map 的长度不能小于 n
+//	max=n       数值不能大于 n，字符串、数组和 map 的长度不能大于 n
+//	oneof=a b c 只能是列表中的值之一，使用空格分隔
+//	regex=expr  字符串需要匹配正则表达式，它必须是最后一个规则，因此表达式中可以包含逗号
+type defaultValidator struct{}
+
+// Validate 校验结构体的字段，返回所有字段的校验错误，错误的类型是 FieldErrors。
+func (d *defaultValidator) Validate(i interface{}) error {
+
+	v := reflect.ValueOf(i)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs FieldErrors
+	t := v.Type()
+	for j := 0; j < t.NumField(); j++ {
+		ft := t.Field(j)
+		tag := ft.Tag.Get("validate")
+		if tag == "" {
+			continue
+		}
+		fv := PatchValue(v.Field(j), true).Interface()
+		if err := validateField(fv, tag); err != nil {
+			errs = append(errs, &FieldError{Field: ft.Name, Err: err})
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// validateField 按照 validate 标签校验字段的值
+func validateField(i interface{}, tag string) error {
+
+	v := reflect.ValueOf(i)
+
+	for tag != "" {
+
+		var rule string
+		if strings.HasPrefix(tag, "regex=") {
+			rule, tag = tag, ""
+		} else if n := strings.Index(tag, ","); n >= 0 {
+			rule, tag = tag[:n], tag[n+1:]
+		} else {
+			rule, tag = tag, ""
+		}
+
+		name, arg := rule, ""
+		if n := strings.Index(rule, "="); n >= 0 {
+			name, arg = rule[:n], rule[n+1:]
+		}
+
+		var err error
+		switch name {
+		case "required":
+			if isZeroValue(v) {
+				err = errors.New("value is required")
+			}
+		case "min", "max":
+			err = validateRange(v, name, arg)
+		case "oneof":
+			err = validateOneOf(i, arg)
+		case "regex":
+			err = validateRegex(i, arg)
+		default:
+			err = fmt.Errorf("unknown validate rule %q", name)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isZeroValue 返回是否是零值，空的字符串、数组和 map 也被当作零值。
+func isZeroValue(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	}
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
+
+// validateRange 校验 min 和 max 规则
+func validateRange(v reflect.Value, name string, arg string) error {
+
+	limit, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return fmt.Errorf("%s=%s isn't a number", name, arg)
+	}
+
+	var (
+		f    float64
+		what = "value"
+	)
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		f = v.Float()
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		f, what = float64(v.Len()), "length"
+	default:
+		return fmt.Errorf("%s can't be applied to %s", name, v.Kind())
+	}
+
+	if name == "min" && f < limit {
+		return fmt.Errorf("%s %v is less than min %s", what, f, arg)
+	}
+	if name == "max" && f > limit {
+		return fmt.Errorf("%s %v is greater than max %s", what, f, arg)
+	}
+	return nil
+}
+
+// validateOneOf 校验 oneof 规则
+func validateOneOf(i interface{}, arg string) error {
+	s := fmt.Sprint(i)
+	for _, option := range strings.Fields(arg) {
+		if s == option {
+			return nil
+		}
+	}
+	return fmt.Errorf("value %s isn't one of [%s]", s, arg)
+}
+
+// validateRegex 校验 regex 规则
+func validateRegex(i interface{}, arg string) error {
+	r, err := regexp.Compile(arg)
+	if err != nil {
+		return err
+	}
+	if s := fmt.Sprint(i); !r.MatchString(s) {
+		return fmt.Errorf("value %s doesn't match %s", s, arg)
+	}
+	return nil
+}
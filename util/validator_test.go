@@ -0,0 +1,91 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/go-spring/spring-core/util"
+)
+
+func TestValidate(t *testing.T) {
+
+	testcases := []struct {
+		value interface{}
+		tag   string
+		err   error
+	}{
+		{0, "", nil},
+		{0, "required", errors.New("value is required")},
+		{"", "required", errors.New("value is required")},
+		{[]int{}, "required", errors.New("value is required")},
+		{8080, "required,min=1,max=65535", nil},
+		{0, "min=1", errors.New("value 0 is less than min 1")},
+		{uint(70000), "max=65535", errors.New("value 70000 is greater than max 65535")},
+		{1.5, "min=0.5,max=2", nil},
+		{"abc", "max=2", errors.New("length 3 is greater than max 2")},
+		{[]string{"a"}, "min=2", errors.New("length 1 is less than min 2")},
+		{"debug", "oneof=debug info warn", nil},
+		{"trace", "oneof=debug info warn", errors.New("value trace isn't one of [debug info warn]")},
+		{"a,b", "required,regex=^[a-z]+(,[a-z]+)*$", nil},
+		{"A", "regex=^[a-z]+$", errors.New("value A doesn't match ^[a-z]+$")},
+		{true, "min=1", errors.New("min can't be applied to bool")},
+		{1, "unknown", errors.New(`unknown validate rule "unknown"`)},
+	}
+
+	for _, c := range testcases {
+		st := reflect.StructOf([]reflect.StructField{{
+			Name: "Field",
+			Type: reflect.TypeOf(c.value),
+			Tag:  reflect.StructTag(fmt.Sprintf(`validate:"%s"`, c.tag)),
+		}})
+		v := reflect.New(st)
+		v.Elem().Field(0).Set(reflect.ValueOf(c.value))
+		err := util.DefaultValidator.Validate(v.Interface())
+		if c.err == nil {
+			util.AssertEqual(t, err, nil)
+		} else {
+			util.AssertEqual(t, err, util.FieldErrors{{Field: "Field", Err: c.err}})
+		}
+	}
+
+	t.Run("struct", func(t *testing.T) {
+		type Inner struct {
+			Name string `validate:"required"`
+		}
+		s := struct {
+			Host  string `validate:"required"`
+			Port  int    `validate:"min=1"`
+			Inner Inner  // 不会递归校验嵌套的结构体
+			level string `validate:"oneof=debug info"`
+		}{Port: 8080, level: "trace"}
+		err := util.DefaultValidator.Validate(s)
+		util.AssertEqual(t, err.Error(), "Host: value is required\nlevel: value trace isn't one of [debug info]")
+		util.AssertEqual(t, util.DefaultValidator.Validate(1), nil)
+	})
+
+	t.Run("no validator", func(t *testing.T) {
+		util.AssertEqual(t, util.GetValidator(), nil)
+		s := struct {
+			Email string `validate:"email"`
+		}{}
+		util.AssertEqual(t, util.Validate(s), nil)
+	})
+}